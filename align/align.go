@@ -0,0 +1,202 @@
+package align
+
+import (
+	"strconv"
+	"strings"
+)
+
+//Alignment is an alignment of a sequence a against a sequence b, starting at
+//the beginning of both. ALength and BLength are how many symbols of a and b it
+//covers. Insertions are symbols of a missing from b and deletions are symbols
+//of b missing from a. CIGAR spells the alignment out with M (match or
+//mismatch), I and D operations, as in SAM and GFA.
+type Alignment struct {
+	ALength    int
+	BLength    int
+	Matches    int
+	Mismatches int
+	Insertions int
+	Deletions  int
+	CIGAR      string
+}
+
+//Distance returns the edit distance of the alignment.
+func (alignment Alignment) Distance() int {
+	return alignment.Mismatches + alignment.Insertions + alignment.Deletions
+}
+
+//Identity returns the fraction of alignment columns that are matches.
+func (alignment Alignment) Identity() float64 {
+	columns := alignment.Matches + alignment.Distance()
+	if columns == 0 {
+		return 0.0
+	}
+	return float64(alignment.Matches) / float64(columns)
+}
+
+//Operations spells out the CIGAR of the alignment one column at a time, e.g.,
+//"MMIM" for 2M1I1M, so that we can walk along both sequences together.
+func (alignment Alignment) Operations() []byte {
+	operations := make([]byte, 0, alignment.ALength+alignment.Deletions)
+	count := 0
+	for i := 0; i < len(alignment.CIGAR); i++ {
+		c := alignment.CIGAR[i]
+		if c >= '0' && c <= '9' {
+			count = 10*count + int(c-'0')
+			continue
+		}
+		for ; count > 0; count-- {
+			operations = append(operations, c)
+		}
+	}
+	return operations
+}
+
+//Global aligns all of a against all of b with the fewest edits, only looking at
+//alignments that never stray more than band symbols off the diagonal. It returns
+//false if there is no such alignment, i.e., if the lengths of a and b differ by
+//more than band.
+func Global(a, b string, band int) (Alignment, bool) {
+	if len(a)-len(b) > band || len(b)-len(a) > band {
+		return Alignment{}, false
+	}
+	return bandedAlignment(a, b, band, false), true
+}
+
+//Overlap aligns all of a against whichever prefix of b fits it best, e.g., the
+//end of one read against the start of the next read. Like Global, it only looks
+//within band symbols of the diagonal. BLength of the result says where the
+//overlap ends in b, which is len(a) give or take the indels. It returns false if
+//b is too short to hold an alignment of a within the band.
+func Overlap(a, b string, band int) (Alignment, bool) {
+	if len(a)-len(b) > band {
+		return Alignment{}, false
+	}
+	return bandedAlignment(a, b, band, true), true
+}
+
+//the moves of the edit distance recurrence, kept for the traceback
+const (
+	moveDiagonal byte = iota
+	moveInsertion
+	moveDeletion
+)
+
+//bandedAlignment fills in the edit distance table of a against b, but only the
+//cells within band of the diagonal; the rest are too far off to matter. If
+//freeEnd is true, the alignment may stop anywhere in b rather than at its end.
+func bandedAlignment(a, b string, band int, freeEnd bool) Alignment {
+	if band < 0 {
+		band = 0
+	}
+	m, n := len(a), len(b)
+	width := 2*band + 1
+	infinity := m + n + 1
+
+	// row i holds columns j = i-band ... i+band at positions 0 ... width-1
+	cost := make([]int, (m+1)*width)
+	move := make([]byte, (m+1)*width)
+	cell := func(i, j int) int {
+		return i*width + j - i + band
+	}
+	inBand := func(i, j int) bool {
+		return j >= 0 && j <= n && j-i >= -band && j-i <= band
+	}
+	get := func(i, j int) int {
+		if !inBand(i, j) {
+			return infinity
+		}
+		return cost[cell(i, j)]
+	}
+
+	for i := 0; i <= m; i++ {
+		for j := i - band; j <= i+band; j++ {
+			if j < 0 || j > n {
+				continue
+			}
+			c := cell(i, j)
+			switch {
+			case i == 0:
+				cost[c], move[c] = j, moveDeletion
+			case j == 0:
+				cost[c], move[c] = i, moveInsertion
+			default:
+				best, bestMove := get(i-1, j-1), moveDiagonal
+				if a[i-1] != b[j-1] {
+					best++
+				}
+				if x := get(i-1, j) + 1; x < best {
+					best, bestMove = x, moveInsertion
+				}
+				if x := get(i, j-1) + 1; x < best {
+					best, bestMove = x, moveDeletion
+				}
+				cost[c], move[c] = best, bestMove
+			}
+		}
+	}
+
+	// where does the alignment end in b?
+	end := n
+	if freeEnd {
+		end = -1
+		for j := m - band; j <= m+band; j++ {
+			if !inBand(m, j) {
+				continue
+			}
+			// prefer the end closest to the diagonal among equally good ones
+			if end < 0 || get(m, j) < get(m, end) || (get(m, j) == get(m, end) && abs(j-m) < abs(end-m)) {
+				end = j
+			}
+		}
+	}
+
+	// walk back from the end, collecting operations in reverse
+	alignment := Alignment{ALength: m, BLength: end}
+	operations := make([]byte, 0, m+band)
+	i, j := m, end
+	for i > 0 || j > 0 {
+		switch move[cell(i, j)] {
+		case moveDiagonal:
+			if a[i-1] == b[j-1] {
+				alignment.Matches++
+			} else {
+				alignment.Mismatches++
+			}
+			operations = append(operations, 'M')
+			i, j = i-1, j-1
+		case moveInsertion:
+			alignment.Insertions++
+			operations = append(operations, 'I')
+			i--
+		case moveDeletion:
+			alignment.Deletions++
+			operations = append(operations, 'D')
+			j--
+		}
+	}
+	alignment.CIGAR = cigar(operations)
+	return alignment
+}
+
+//cigar run-length encodes a list of operations given in reverse order.
+func cigar(reversed []byte) string {
+	var builder strings.Builder
+	for end := len(reversed); end > 0; {
+		start := end - 1
+		for start > 0 && reversed[start-1] == reversed[end-1] {
+			start--
+		}
+		builder.WriteString(strconv.Itoa(end - start))
+		builder.WriteByte(reversed[end-1])
+		end = start
+	}
+	return builder.String()
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
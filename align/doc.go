@@ -0,0 +1,5 @@
+//Package align aligns two sequences with banded edit distance, reporting where
+//the alignment ends in each sequence, its identity and a CIGAR string. The
+//assemblers use it to check overlaps between reads with sequencing errors,
+//including insertions and deletions, which shift where an overlap ends.
+package align
@@ -0,0 +1,131 @@
+package assembly
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+//WriteScaffoldsToFile writes scaffolds to a FASTA file. See WriteScaffoldsFASTA
+//for the format.
+func WriteScaffoldsToFile(scaffolds []Scaffold, outFilename string, lineWidth int) error {
+	outFile, err := os.Create(outFilename)
+	if err != nil {
+		return err
+	}
+	err = WriteScaffoldsFASTA(outFile, scaffolds, lineWidth)
+	closeErr := outFile.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+//WriteScaffoldsFASTA writes scaffolds as FASTA records, with their length and
+//number of contigs in the header:
+//
+//	>scaffold_1 len=148211 contigs=3
+//
+//Sequence lines are wrapped every lineWidth symbols; lineWidth <= 0 puts each
+//sequence on one line.
+func WriteScaffoldsFASTA(w io.Writer, scaffolds []Scaffold, lineWidth int) error {
+	out := bufio.NewWriter(w)
+	for _, scaffold := range scaffolds {
+		description := fmt.Sprintf("len=%d contigs=%d", len(scaffold.Sequence), len(scaffold.Contigs))
+		seqio.WriteFASTA(out, scaffold.ID, description, scaffold.Sequence, lineWidth)
+	}
+	return out.Flush()
+}
+
+//WriteAGPToFile writes scaffolds to an AGP file. See WriteAGP for the format.
+func WriteAGPToFile(scaffolds []Scaffold, contigs []Contig, outFilename string) error {
+	outFile, err := os.Create(outFilename)
+	if err != nil {
+		return err
+	}
+	err = WriteAGP(outFile, scaffolds, contigs)
+	closeErr := outFile.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+//WriteAGP writes how scaffolds are built from contigs in AGP 2.1, the format
+//genome archives use for this. Every contig is a W (sequence) line, with the
+//contig ID (contig_1, contig_2, ... by index if it has none) and its
+//orientation, and every open gap between contigs is an N line for a scaffold
+//gap whose size we estimated from paired ends:
+//
+//	scaffold_1	1	52010	1	W	contig_4	1	52010	+
+//	scaffold_1	52011	52230	2	N	220	scaffold	yes	paired-ends
+//	scaffold_1	52231	148211	3	W	contig_1	1	95981	-
+//
+//The fill of a closed gap (see FillGaps) is a W line of its own, named as in
+//ScaffoldFills; if the contigs around a closed gap overlap, the second one is
+//trimmed. Coordinates are 1-based and inclusive, as AGP wants.
+func WriteAGP(w io.Writer, scaffolds []Scaffold, contigs []Contig) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "##agp-version\t2.1")
+	for _, scaffold := range scaffolds {
+		position, part := 1, 1
+		for i, c := range scaffold.Contigs {
+			length := len(contigs[c].Sequence)
+			start, end := 1, length // the part of the contig in the scaffold
+			if i > 0 {
+				gap := scaffold.Gaps[i-1]
+				switch {
+				case !scaffold.Closed[i-1]:
+					gap = gapLength(gap)
+					fmt.Fprintf(out, "%s\t%d\t%d\t%d\tN\t%d\tscaffold\tyes\tpaired-ends\n", scaffold.ID, position, position+gap-1, part, gap)
+					position += gap
+					part++
+				case gap < 0 && scaffold.Reverse[i]:
+					end += gap
+				case gap < 0:
+					start -= gap
+				case gap > 0:
+					fmt.Fprintf(out, "%s\t%d\t%d\t%d\tW\t%s\t1\t%d\t+\n", scaffold.ID, position, position+gap-1, part, FillID(scaffold, i-1), gap)
+					position += gap
+					part++
+				}
+			}
+			id := contigs[c].ID
+			if id == "" {
+				id = ContigID(c)
+			}
+			orientation := "+"
+			if scaffold.Reverse[i] {
+				orientation = "-"
+			}
+			fmt.Fprintf(out, "%s\t%d\t%d\t%d\tW\t%s\t%d\t%d\t%s\n", scaffold.ID, position, position+end-start, part, id, start, end, orientation)
+			position += end - start + 1
+			part++
+		}
+	}
+	return out.Flush()
+}
+
+//FillID names the fill of the i-th gap of a scaffold, e.g., scaffold_1_fill_2.
+func FillID(scaffold Scaffold, i int) string {
+	return scaffold.ID + "_fill_" + strconv.Itoa(i+1)
+}
+
+//ScaffoldFills returns the fills of the closed gaps of scaffolds that aren't
+//empty, named by FillID, e.g., to write them out along with an AGP file that
+//refers to them.
+func ScaffoldFills(scaffolds []Scaffold) []seqio.Read {
+	fills := make([]seqio.Read, 0)
+	for _, scaffold := range scaffolds {
+		for i, fill := range scaffold.Fills {
+			if scaffold.Closed[i] && fill != "" {
+				fills = append(fills, seqio.Read{ID: FillID(scaffold, i), Sequence: fill, Multiplicity: 1})
+			}
+		}
+	}
+	return fills
+}
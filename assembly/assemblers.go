@@ -0,0 +1,392 @@
+package assembly
+
+import (
+	"errors"
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/index"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+//ErrAssemblyStuck means a k-mer reconstruction couldn't place all of its k-mers.
+var ErrAssemblyStuck = errors.New("assembly could not use every k-mer")
+
+//GenomeAssembler1 takes a collection of strings and returns a genome whose
+//k-mer composition is these strings. It makes the following assumptions.
+//1. "Perfect coverage" -- every k-mer is detected
+//2. No errors in reads
+//3. Every read has equal length (k)
+//4. DNA is single-stranded
+//5. (No k-mer repeats)
+func GenomeAssembler1(kmers []string) (string, error) {
+	// greedy algorithm: look for whatever helps me the most (overlap of k-1 symbols).
+	// first, what is k? length of first read (and every other read, hopefully)
+	k, err := CheckKmers(kmers)
+	if err != nil {
+		return "", err
+	}
+	// start with arbitrary kmer
+
+	genome := kmers[len(kmers)/2] // midpoint k-mer
+
+	// let's throw out everything we have used
+	kmers = Remove(kmers, len(kmers)/2)
+
+	// while we still have reads, try to extend current read
+	for len(kmers) > 0 {
+		// note: we need to remember to delete any kmer we use or else hit an infinite loop
+		// (and if no k-mer fits anywhere, we'd loop forever too, so stop and say so)
+		extended := false
+		for i, kmer := range kmers {
+			// try to extend genome to left and right
+			// a hit means that we match k-1 nucleotides to end of genome
+			if genome[0:k-1] == kmer[1:] { // extending left
+				// update genome by adding first symbol of kmer to left
+				genome = kmer[0:1] + genome
+				// throw out read
+				kmers = Remove(kmers, i)
+				extended = true
+				// stop the for loop so we don't have an index out of bounds error
+				break // breaks innermost loop you are in
+			} else if genome[len(genome)-k+1:len(genome)] == kmer[:k-1] { // extending right
+				genome = genome + kmer[k-1:]
+				kmers = Remove(kmers, i)
+				extended = true
+				break
+			}
+		}
+		if !extended {
+			return genome, fmt.Errorf("%w: %d k-mers left over", ErrAssemblyStuck, len(kmers))
+		}
+	}
+
+	return genome, nil
+}
+
+//CheckKmers makes sure a k-mer composition is something we can assemble:
+//non-empty, and every k-mer the same length k >= 2. It returns k.
+func CheckKmers(kmers []string) (int, error) {
+	if len(kmers) == 0 {
+		return 0, fmt.Errorf("GenomeAssembler: %w (empty k-mer composition)", walker.ErrNoReads)
+	}
+	k := len(kmers[0])
+	for i, kmer := range kmers {
+		if len(kmer) != k || k < 2 {
+			return 0, &walker.ReadLengthError{Index: i, ID: kmer, Length: len(kmer), Required: k}
+		}
+	}
+	return k, nil
+}
+
+//Remove takes a collection of strings and an index.
+//It removes the string at the given index and returns the updated array.
+func Remove(patterns []string, index int) []string {
+	// remember our trick for deleting an element
+	patterns = append(patterns[:index], patterns[index+1:]...)
+	return patterns
+}
+
+// issue 1: current assembler is slowwwwwww (won't scale to 3M bp)
+// reason why is because most of the time, when it is looking for a match, it can't find one.
+// this gets worse the bigger the genome gets.
+// solution: build prefix and suffix indices before looking for matches.
+
+func GenomeAssembler2(kmers []string) (string, error) {
+	k, err := CheckKmers(kmers)
+	if err != nil {
+		return "", err
+	}
+	genome := kmers[len(kmers)/2]
+
+	//build a prefix and suffix index
+	indexLength := k - 1
+
+	fmt.Println("Building indices.")
+	kmerReads := seqio.ReadsFromStrings(kmers)
+	prefixIndex, err := index.BuildPrefixIndex(kmerReads, indexLength)
+	if err != nil {
+		return "", err
+	}
+	fmt.Println("Prefix index built.")
+	suffixIndex, err := index.BuildSuffixIndex(kmerReads, indexLength)
+	if err != nil {
+		return "", err
+	}
+	fmt.Println("Suffix index built. Ready to assemble!")
+
+	// while we continue to find things, keep going
+	keepLooping := true
+	counter := 0
+	for keepLooping == true {
+		keepLooping = false
+		// update keepLooping to true when we find an overlap
+		// first, check the right side of genome
+		prefix := genome[len(genome)-k+1:]
+		// is prefix in the prefix index?
+		matches1 := prefixIndex.Lookup(prefix)
+		if len(matches1) > 0 { // match found :)
+			// make sure we keep going!
+			keepLooping = true
+			// where do I need to look in my kmers?
+			nextRead := kmers[matches1[0]] // always take the first match we see
+			genome = genome + nextRead[k-1:]
+			counter++
+			if counter%100000 == 0 {
+				fmt.Println("Update: We have overlapped", counter, "reads.")
+			}
+		}
+		// now, try to extend to the left too
+		suffix := genome[:k-1]
+		matches2 := suffixIndex.Lookup(suffix)
+		if len(matches2) > 0 { // we found a match
+			keepLooping = true
+			prevRead := kmers[matches2[0]]
+			// extend genome left
+			genome = prevRead[:len(prevRead)-(k-1)] + genome
+			counter++
+			if counter%100000 == 0 {
+				fmt.Println("Update: We have overlapped", counter, "reads.")
+			}
+		}
+	}
+
+	return genome, nil
+}
+
+// part 3: relaxing the assumptions of "perfect coverage" and equal read lengths
+// how well does a collection of reads "cover" a genome?
+// first, we should be able to simulate a dataset to test the algorithm we develop
+// minMatchLength is the minimum perfect match we will allow between overlapping reads.
+// minMatchLength must be bigger than the index length
+// now we will produce contigs too.
+// these reads now have variable length (bigger than indexLength)
+// every contig remembers which reads went into it, so we can trace it back later,
+// and every overlap we verify along the way is recorded in the assembly graph.
+// if doubleStranded is true, reads may come from either strand (see DoubleStrandedReads).
+// when the reads that could extend a contig disagree, policy says what to do (see TiePolicy).
+// contigs shorter than minContigLength symbols are set aside rather than kept (see Assembly).
+
+func GenomeAssembler3(reads []seqio.Read, minMatchLength, indexLength, minContigLength int, policy TiePolicy, doubleStranded bool) (*Assembly, error) {
+	err := CheckAssemblyParameters(reads, minMatchLength, indexLength)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixIndex, suffixIndex, err := buildEndIndices(reads, indexLength)
+	if err != nil {
+		return nil, err
+	}
+	return GenomeAssembler3Indexed(reads, prefixIndex, suffixIndex, minMatchLength, minContigLength, policy, doubleStranded)
+}
+
+//GenomeAssembler3Indexed is GenomeAssembler3 for reads whose prefix and suffix
+//indices are already built, e.g., by index.IndexReadStream while the reads were
+//read in; the index length is theirs. The indices must cover reads as given.
+//With reads from both strands, the reverse complements are added to them.
+func GenomeAssembler3Indexed(reads []seqio.Read, prefixIndex, suffixIndex *index.EndIndex, minMatchLength, minContigLength int, policy TiePolicy, doubleStranded bool) (*Assembly, error) {
+	err := checkEndIndices(reads, prefixIndex, suffixIndex, minMatchLength)
+	if err != nil {
+		return nil, err
+	}
+	indexLength := prefixIndex.Length
+
+	strandReads := indexStrands(reads, prefixIndex, suffixIndex, doubleStranded)
+	find := prefixCandidateFinder(prefixIndex, suffixIndex, strandReads, minMatchLength, indexLength, ExactVerifier)
+	return assembleWithFinder(reads, strandReads, find, indexLength, ExactVerifier, policy, minContigLength, doubleStranded), nil
+}
+
+//buildEndIndices builds the prefix and suffix indices the read assemblers look
+//overlaps up in, for the reads as given (see indexStrands for the other strand).
+func buildEndIndices(reads []seqio.Read, indexLength int) (*index.EndIndex, *index.EndIndex, error) {
+	fmt.Println("Building a prefix and suffix index for reads.")
+	prefixIndex, err := index.BuildPrefixIndex(reads, indexLength)
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Println("Prefix index built!")
+	suffixIndex, err := index.BuildSuffixIndex(reads, indexLength)
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Println("Suffix index built!")
+	return prefixIndex, suffixIndex, nil
+}
+
+//checkEndIndices checks the inputs of the assemblers that are handed their
+//indices: as CheckAssemblyParameters, with the index length taken from the
+//indices, which must both be there, be as long as each other and cover reads.
+func checkEndIndices(reads []seqio.Read, prefixIndex, suffixIndex *index.EndIndex, minMatchLength int) error {
+	if prefixIndex == nil {
+		return &walker.ParameterError{Name: "prefixIndex", Value: nil, Reason: "must not be nil"}
+	}
+	if suffixIndex == nil {
+		return &walker.ParameterError{Name: "suffixIndex", Value: nil, Reason: "must not be nil"}
+	}
+	if prefixIndex.Length != suffixIndex.Length {
+		return &walker.ParameterError{Name: "suffixIndex", Value: suffixIndex.Length, Reason: fmt.Sprintf("must have the same length as prefixIndex (%d)", prefixIndex.Length)}
+	}
+	err := CheckAssemblyParameters(reads, minMatchLength, prefixIndex.Length)
+	if err != nil {
+		return err
+	}
+	for i, read := range reads {
+		if len(read.Sequence) < prefixIndex.Length {
+			return &walker.ReadLengthError{Index: i, ID: read.ID, Length: len(read.Sequence), Required: prefixIndex.Length}
+		}
+	}
+	return nil
+}
+
+//indexStrands returns the reads the assemblers work on (see DoubleStrandedReads).
+//With reads from both strands, it files the reverse complement of every read in
+//the indices of the reads as given, under the index it has in the returned
+//reads. The indices end up just as if they had been built for those reads, but
+//the reads' ends were only read once.
+func indexStrands(reads []seqio.Read, prefixIndex, suffixIndex *index.EndIndex, doubleStranded bool) []seqio.Read {
+	if !doubleStranded {
+		return reads
+	}
+	strandReads := DoubleStrandedReads(reads)
+	n := len(reads)
+	length := prefixIndex.Length
+	for i := n; i < 2*n; i++ {
+		read := strandReads[i].Sequence
+		prefixIndex.Add(read[:length], i)
+		suffixIndex.Add(read[len(read)-length:], i)
+	}
+	return strandReads
+}
+
+//CheckAssemblyParameters checks the inputs shared by the read assemblers:
+//there must be reads, and minMatchLength must be bigger than indexLength
+//(which must be positive). Read lengths are checked when the indices are built.
+func CheckAssemblyParameters(reads []seqio.Read, minMatchLength, indexLength int) error {
+	if len(reads) == 0 {
+		return fmt.Errorf("GenomeAssembler: %w", walker.ErrNoReads)
+	}
+	if indexLength < 1 {
+		return &walker.ParameterError{Name: "indexLength", Value: indexLength, Reason: "must be positive"}
+	}
+	if minMatchLength <= indexLength {
+		return &walker.ParameterError{Name: "minMatchLength", Value: minMatchLength, Reason: "must be bigger than indexLength"}
+	}
+	return nil
+}
+
+//JoinContigs takes the results of extending the same starting read to the left
+//and to the right, and glues them into a single contig. The left contig ends
+//with the starting read and the right contig begins with it, so we must only
+//count it once.
+func JoinContigs(left, right Contig, seedLength int) Contig {
+	contig := Contig{
+		Sequence: left.Sequence + right.Sequence[seedLength:],
+		Reads:    append(append([]int{}, left.Reads...), right.Reads[1:]...),
+		Overlaps: append(append([]int{}, left.Overlaps...), right.Overlaps...),
+	}
+	if left.Contained != nil || right.Contained != nil {
+		contig.Contained = make([]bool, 0, len(contig.Reads))
+		for j := range left.Reads {
+			contig.Contained = append(contig.Contained, left.IsContained(j))
+		}
+		for j := 1; j < len(right.Reads); j++ {
+			contig.Contained = append(contig.Contained, right.IsContained(j))
+		}
+	}
+	return contig
+}
+
+//ExtendContigRight takes the index of an initial read (currentReadIndex) along with everything we need for assembly. It iteratively extends our initial string to the right by looking for exact matches in the prefix index, choosing among the reads that match as policy says (see TiePolicy). As it goes, it marks the reads it places in used, and records every overlap it verifies in graph (which may be nil). It returns the contig, which begins with the initial read, and the reads it could have gone on with if it stopped because they disagreed.
+func ExtendContigRight(currentReadIndex int, prefixIndex, suffixIndex *index.EndIndex, reads []seqio.Read, minMatchLength, indexLength int, policy TiePolicy, used *ReadTracker, graph *AssemblyGraph) (Contig, []int) {
+	find := prefixCandidateFinder(prefixIndex, suffixIndex, reads, minMatchLength, indexLength, ExactVerifier)
+	return extendContig(currentReadIndex, find, reads, indexLength, ExactVerifier, policy, used, graph, false)
+}
+
+//ExtendContigLeft takes the index of an initial read (currentReadIndex) along with everything we need for assembly. It iteratively extends our initial string to the left by looking for exact matches in the suffix index, choosing among the reads that match as policy says (see TiePolicy). As it goes, it marks the reads it places in used, and records every overlap it verifies in graph (which may be nil). It returns the contig, which ends with the initial read, and the reads it could have gone on with if it stopped because they disagreed.
+func ExtendContigLeft(currentReadIndex int, prefixIndex, suffixIndex *index.EndIndex, reads []seqio.Read, minMatchLength, indexLength int, policy TiePolicy, used *ReadTracker, graph *AssemblyGraph) (Contig, []int) {
+	find := prefixCandidateFinder(prefixIndex, suffixIndex, reads, minMatchLength, indexLength, ExactVerifier)
+	return extendContig(currentReadIndex, find, reads, indexLength, ExactVerifier, policy, used, graph, true)
+}
+
+//GenomeAssembler4 is GenomeAssembler3 for reads with sequencing errors: overlaps
+//only need to share about as many k-mers as two copies of the same string with
+//errorRate errors would (see SharedKmerOverlap) instead of matching exactly.
+func GenomeAssembler4(reads []seqio.Read, minMatchLength, indexLength, minContigLength int, errorRate float64, k int, policy TiePolicy, doubleStranded bool) (*Assembly, error) {
+	if errorRate < 0.0 || errorRate >= 1.0 {
+		return nil, &walker.ParameterError{Name: "errorRate", Value: errorRate, Reason: "must be in [0, 1)"}
+	}
+	if k < 1 || k > minMatchLength {
+		return nil, &walker.ParameterError{Name: "k", Value: k, Reason: "must be between 1 and minMatchLength"}
+	}
+	return GenomeAssembler4WithVerifier(reads, minMatchLength, indexLength, minContigLength, SharedKmerVerifier(errorRate, k), policy, doubleStranded)
+}
+
+//GenomeAssembler4WithVerifier is GenomeAssembler4 with the overlap test swapped
+//out: a candidate overlap is accepted when verify says so. AlignmentVerifier
+//checks overlaps by aligning them, which copes with insertions and deletions.
+func GenomeAssembler4WithVerifier(reads []seqio.Read, minMatchLength, indexLength, minContigLength int, verify OverlapVerifier, policy TiePolicy, doubleStranded bool) (*Assembly, error) {
+	err := CheckAssemblyParameters(reads, minMatchLength, indexLength)
+	if err != nil {
+		return nil, err
+	}
+	if verify == nil {
+		return nil, &walker.ParameterError{Name: "verify", Value: nil, Reason: "must not be nil"}
+	}
+
+	prefixIndex, suffixIndex, err := buildEndIndices(reads, indexLength)
+	if err != nil {
+		return nil, err
+	}
+	return GenomeAssembler4Indexed(reads, prefixIndex, suffixIndex, minMatchLength, minContigLength, verify, policy, doubleStranded)
+}
+
+//GenomeAssembler4Indexed is GenomeAssembler4WithVerifier for reads whose prefix
+//and suffix indices are already built, as for GenomeAssembler3Indexed.
+func GenomeAssembler4Indexed(reads []seqio.Read, prefixIndex, suffixIndex *index.EndIndex, minMatchLength, minContigLength int, verify OverlapVerifier, policy TiePolicy, doubleStranded bool) (*Assembly, error) {
+	err := checkEndIndices(reads, prefixIndex, suffixIndex, minMatchLength)
+	if err != nil {
+		return nil, err
+	}
+	if verify == nil {
+		return nil, &walker.ParameterError{Name: "verify", Value: nil, Reason: "must not be nil"}
+	}
+	indexLength := prefixIndex.Length
+
+	strandReads := indexStrands(reads, prefixIndex, suffixIndex, doubleStranded)
+	find := prefixCandidateFinder(prefixIndex, suffixIndex, strandReads, minMatchLength, indexLength, verify)
+	return assembleWithFinder(reads, strandReads, find, indexLength, verify, policy, minContigLength, doubleStranded), nil
+}
+
+//DefaultSharedKmerConfidence is the confidence SharedKmerOverlap uses: it
+//accepts 95% of true overlaps.
+const DefaultSharedKmerConfidence = 0.95
+
+//SharedKmerOverlap decides whether two overlapping stretches of erroneous reads
+//plausibly come from the same place in the genome, by comparing how many k-mers
+//they share to how many we'd expect two noisy copies of the same string to share
+//(see kmer.SharedKmerThreshold).
+func SharedKmerOverlap(str1, str2 string, errorRate float64, k int) bool {
+	return SharedKmerOverlapWithModel(str1, str2, kmer.SubstitutionModel(errorRate), k, DefaultSharedKmerConfidence)
+}
+
+//SharedKmerOverlapWithModel is SharedKmerOverlap for reads whose errors are
+//described by model: the stretches overlap if they share at least as many k-mers
+//as two copies of the same string do with probability confidence.
+func SharedKmerOverlapWithModel(str1, str2 string, model kmer.ErrorModel, k int, confidence float64) bool {
+	return float64(kmer.CountSharedKmers(str1, str2, k)) >= kmer.SharedKmerThreshold(len(str1), model, k, confidence)
+}
+
+//ExtendContigRightInexact is ExtendContigRight for reads with errors: a read
+//whose prefix is found in the index is a candidate if verify says it overlaps.
+func ExtendContigRightInexact(currentReadIndex int, prefixIndex, suffixIndex *index.EndIndex, reads []seqio.Read, minMatchLength, indexLength int, verify OverlapVerifier, policy TiePolicy, used *ReadTracker, graph *AssemblyGraph) (Contig, []int) {
+	find := prefixCandidateFinder(prefixIndex, suffixIndex, reads, minMatchLength, indexLength, verify)
+	return extendContig(currentReadIndex, find, reads, indexLength, verify, policy, used, graph, false)
+}
+
+//ExtendContigLeftInexact is ExtendContigLeft for reads with errors: a read
+//whose suffix is found in the index is a candidate if verify says it overlaps.
+func ExtendContigLeftInexact(currentReadIndex int, prefixIndex, suffixIndex *index.EndIndex, reads []seqio.Read, minMatchLength, indexLength int, verify OverlapVerifier, policy TiePolicy, used *ReadTracker, graph *AssemblyGraph) (Contig, []int) {
+	find := prefixCandidateFinder(prefixIndex, suffixIndex, reads, minMatchLength, indexLength, verify)
+	return extendContig(currentReadIndex, find, reads, indexLength, verify, policy, used, graph, true)
+}
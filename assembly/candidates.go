@@ -0,0 +1,372 @@
+package assembly
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kaushikvemparala/Walker/index"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// at every step of extending a contig, the index usually offers several reads
+// that overlap its end. Most of them come from the same place in the genome and
+// agree with one another about what comes next; taking any one of them is fine.
+// But at a repeat, some of them come from another copy of the repeat and
+// disagree with the rest as soon as the repeat ends. Grabbing whichever read the
+// index happens to list first then walks into the wrong copy half the time. So
+// we gather every candidate, score it, and check whether the candidates agree
+// before going on.
+
+//TiePolicy says what to do when the reads that could extend a contig disagree
+//about what comes next, which is what a repeat looks like.
+type TiePolicy int
+
+const (
+	//StopAtTie ends the contig where the candidates disagree.
+	StopAtTie TiePolicy = iota
+	//BestCandidate follows the best candidate (see OverlapCandidate) anyway.
+	BestCandidate
+	//BranchAtTie ends the contig where the candidates disagree, and the
+	//assembler starts its next contigs from the candidates, so that every way
+	//out of the repeat gets a contig of its own.
+	BranchAtTie
+)
+
+//OverlapCandidate is a read that overlaps the end of a contig, scored by how
+//well it fits. Candidates are ranked by their support (the number of other
+//candidates that agree with them about what comes next), then by identity, then
+//by overlap length, and then by extension length: of two reads starting at the
+//same place, the one containing the other goes first.
+//
+//Unless the policy is BestCandidate, reads already placed in a contig are
+//candidates too: they can't be taken again, but they still count when we check
+//whether the candidates agree. At a repeat, the reads from the other copies are
+//often placed already, and they are the ones that disagree.
+type OverlapCandidate struct {
+	Read     int     // index of the read
+	Overlap  int     // number of symbols of the read overlapping the contig
+	Identity float64 // fraction of the overlap that matches (1 for exact overlaps)
+	Support  int
+
+	extension string // the part of the read past the end of the contig, read away from the contig
+	placed    bool   // whether the read is used already
+}
+
+//better reports whether candidate a ranks ahead of candidate b.
+func better(a, b OverlapCandidate) bool {
+	if a.Support != b.Support {
+		return a.Support > b.Support
+	}
+	if a.Identity != b.Identity {
+		return a.Identity > b.Identity
+	}
+	if a.Overlap != b.Overlap {
+		return a.Overlap > b.Overlap
+	}
+	if len(a.extension) != len(b.extension) {
+		return len(a.extension) > len(b.extension)
+	}
+	return a.Read < b.Read
+}
+
+//candidateFinder lists the reads that overlap the current read (read
+//currentReadIndex) well enough to extend it (to the left if left is true), and
+//the unused reads lying inside it, as findCandidates does. Used reads are only
+//listed, marked as placed, if withPlaced is true.
+type candidateFinder func(currentReadIndex int, currentRead string, used *ReadTracker, withPlaced, left bool) ([]OverlapCandidate, []int)
+
+//prefixCandidateFinder returns a candidateFinder that looks reads up in the
+//prefix and suffix indices (see findCandidates).
+func prefixCandidateFinder(prefixIndex, suffixIndex *index.EndIndex, reads []seqio.Read, minMatchLength, indexLength int, verify OverlapVerifier) candidateFinder {
+	return func(_ int, currentRead string, used *ReadTracker, withPlaced, left bool) ([]OverlapCandidate, []int) {
+		return findCandidates(currentRead, prefixIndex, suffixIndex, reads, minMatchLength, indexLength, verify, used, withPlaced, left)
+	}
+}
+
+//findCandidates returns every unused read in the indices that overlaps the
+//current read by at least minMatchLength symbols according to verify: reads
+//whose prefix appears in the current read, or whose suffix does if left is true.
+//If withPlaced is true, it returns the used reads that overlap it too, marked
+//as placed. A read is only listed once, with its longest overlap. It also
+//returns the unused reads it finds lying inside the current read.
+func findCandidates(currentRead string, prefixIndex, suffixIndex *index.EndIndex, reads []seqio.Read, minMatchLength, indexLength int, verify OverlapVerifier, used *ReadTracker, withPlaced, left bool) ([]OverlapCandidate, []int) {
+	candidates := make([]OverlapCandidate, 0)
+	contained := make([]int, 0)
+	found := make(map[int]bool)
+	n := len(currentRead)
+	// the places in the current read where indexed ends occur
+	var matches []index.EndMatch
+	if left {
+		matches = suffixIndex.Matches(currentRead)
+	} else {
+		matches = prefixIndex.Matches(currentRead)
+	}
+	// range over all possible overlap lengths, longest first
+	for m := range matches {
+		match := matches[m]
+		j := match.Position
+		if left {
+			// j counts from the right end of the current read, so we go through
+			// the suffixes from the last one back
+			match = matches[len(matches)-1-m]
+			j = n - match.Position - indexLength
+		}
+		// at j == 0 a read starts (or ends) where the current read does, so it
+		// either lies inside the current read or contains it and sticks out
+		if j > n-minMatchLength {
+			continue
+		}
+		for _, i := range match.Reads {
+			if found[i] || (used.IsUsed(i) && !withPlaced) {
+				continue
+			}
+			candidate, inside, ok := checkCandidate(currentRead, i, j, reads, verify, left)
+			if !ok {
+				continue
+			}
+			found[i] = true
+			if !inside {
+				candidate.placed = used.IsUsed(i)
+				candidates = append(candidates, candidate)
+			} else if !used.IsUsed(i) {
+				contained = append(contained, i)
+			}
+		}
+	}
+	return candidates, contained
+}
+
+//checkCandidate asks verify whether read i, starting j symbols into the current
+//read (or ending j symbols before its end if left is true), overlaps it. It
+//returns the candidate, or true if the read lies inside the current read, and
+//false if it doesn't overlap after all.
+func checkCandidate(currentRead string, i, j int, reads []seqio.Read, verify OverlapVerifier, left bool) (OverlapCandidate, bool, bool) {
+	n := len(currentRead)
+	// the verifier checks the end of one read against the start of another,
+	// so to extend left we read both strings backwards
+	overlapping, read := currentRead[j:], reads[i].Sequence
+	if left {
+		overlapping, read = kmer.Reverse(currentRead[:n-j]), kmer.Reverse(read)
+	}
+	// a read that doesn't stick out past the current read can't extend it,
+	// but it may lie inside it
+	if len(read) <= len(overlapping) {
+		_, _, ok := verify(overlapping[:len(read)], read)
+		return OverlapCandidate{}, true, ok
+	}
+	overlap, identity, ok := verify(overlapping, read)
+	if !ok || overlap >= len(read) {
+		return OverlapCandidate{}, false, false
+	}
+	return OverlapCandidate{
+		Read:      i,
+		Overlap:   overlap,
+		Identity:  identity,
+		extension: read[overlap:],
+	}, false, true
+}
+
+//agree reports whether two candidates tell the same story past the end of the
+//contig: the shorter extension must overlap the start of the longer one
+//according to verify. Extensions shorter than indexLength are too short to
+//disagree.
+func agree(a, b OverlapCandidate, indexLength int, verify OverlapVerifier) bool {
+	shorter, longer := a.extension, b.extension
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	if len(shorter) < indexLength {
+		return true
+	}
+	_, _, ok := verify(shorter, longer)
+	return ok
+}
+
+//rankCandidates counts the support of every candidate, sorts the candidates
+//best first, and reports whether any two of them disagree. Within a repeat, the
+//best candidate often stops short of the end of the repeat, so it agrees with
+//the reads from every copy, while those reads already disagree with each other
+//about what comes after it.
+func rankCandidates(candidates []OverlapCandidate, indexLength int, verify OverlapVerifier) bool {
+	tied := false
+	for a := range candidates {
+		for b := a + 1; b < len(candidates); b++ {
+			if agree(candidates[a], candidates[b], indexLength, verify) {
+				candidates[a].Support++
+				candidates[b].Support++
+			} else {
+				tied = true
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		return better(candidates[a], candidates[b])
+	})
+	return tied
+}
+
+//extendContig does the work of the ExtendContig functions, extending to the
+//left if left is true with the reads find comes up with, and marking the reads
+//it places in used. It returns the contig and, if it stopped because the
+//candidates disagreed (see TiePolicy), the candidates, best first.
+func extendContig(currentReadIndex int, find candidateFinder, reads []seqio.Read, indexLength int, verify OverlapVerifier, policy TiePolicy, used *ReadTracker, graph *AssemblyGraph, left bool) (Contig, []int) {
+	currentRead := reads[currentReadIndex].Sequence
+	contig := NewContig(currentReadIndex, currentRead)
+
+	// reads that agree with the contig and stick out past its end, but start
+	// before the current read, so find won't list them again (see below)
+	pending := make([]OverlapCandidate, 0)
+
+	// while we can keep going
+	for {
+		// used reads only matter when we check whether the candidates agree
+		candidates, contained := find(currentReadIndex, currentRead, used, policy != BestCandidate, left)
+		// reads inside the current read are placed along with it
+		for _, i := range contained {
+			used.Use(i)
+			contig.AddContainedRead(i, left)
+		}
+		if len(candidates) == 0 {
+			return contig, nil
+		}
+		// every overlap with an unused read goes into the graph, whether or not
+		// we take it
+		for _, candidate := range candidates {
+			if candidate.placed {
+				continue
+			}
+			if left {
+				graph.AddLink(candidate.Read, currentReadIndex, candidate.Overlap)
+			} else {
+				graph.AddLink(currentReadIndex, candidate.Read, candidate.Overlap)
+			}
+		}
+
+		// whether the candidates agree is up to all of them, placed or not, but
+		// we can only go on with an unused one
+		tied := rankCandidates(candidates, indexLength, verify)
+		unplaced := make([]OverlapCandidate, 0, len(candidates))
+		for _, candidate := range candidates {
+			if !candidate.placed {
+				unplaced = append(unplaced, candidate)
+			}
+		}
+		if tied && policy != BestCandidate {
+			branches := make([]int, len(unplaced))
+			for i, candidate := range unplaced {
+				branches[i] = candidate.Read
+			}
+			return contig, branches
+		}
+		if len(unplaced) == 0 {
+			return contig, nil
+		}
+
+		best := unplaced[0]
+		matchedRead := reads[best.Read].Sequence
+		if left {
+			contig.PrependRead(best.Read, matchedRead, best.Overlap)
+		} else {
+			contig.AppendRead(best.Read, matchedRead, best.Overlap)
+		}
+		used.Use(best.Read)
+		// so are the other reads that agree with the best one but stop before it
+		// does: they lie inside the contig once we take it. Those that stick out
+		// further will mostly turn up again, starting inside the best one. But
+		// with errors, a read starting before the best one may rank lower, and we
+		// hold on to it until a read we take reaches past it
+		next := make([]OverlapCandidate, 0, len(pending))
+		held := make(map[int]bool)
+		for _, candidate := range append(unplaced, pending...) {
+			if candidate.Read == best.Read || used.IsUsed(candidate.Read) || held[candidate.Read] || !agree(candidate, best, indexLength, verify) {
+				continue
+			}
+			if len(candidate.extension) <= len(best.extension) {
+				used.Use(candidate.Read)
+				contig.AddContainedRead(candidate.Read, left)
+			} else if candidate.Overlap > best.Overlap {
+				candidate.Overlap += len(best.extension)
+				candidate.extension = candidate.extension[len(best.extension):]
+				next = append(next, candidate)
+				held[candidate.Read] = true
+			}
+		}
+		pending = next
+		//update currentRead
+		currentReadIndex = best.Read
+		currentRead = matchedRead
+	}
+}
+
+//nextSeed picks the read to start the next contig from: the first read waiting
+//in seeds (e.g., a branch left behind by BranchAtTie) that is still unused,
+//or else the first unused read. It returns the read, what is left of seeds, and
+//false if every read has been used.
+func nextSeed(seeds []int, used *ReadTracker) (int, []int, bool) {
+	for len(seeds) > 0 {
+		seed := seeds[0]
+		seeds = seeds[1:]
+		if !used.IsUsed(seed) {
+			return seed, seeds, true
+		}
+	}
+	seed, ok := used.Next()
+	return seed, seeds, ok
+}
+
+//assembleWithFinder runs the greedy assembly of GenomeAssembler3 and
+//GenomeAssembler4 over reads (whose strands, if doubleStranded, are
+//strandReads, as in DoubleStrandedReads) with the candidates find comes up
+//with: starting from the first unused read, extend it right and left as far as
+//possible, keep the contig, and start over until every read is used.
+func assembleWithFinder(reads, strandReads []seqio.Read, find candidateFinder, indexLength int, verify OverlapVerifier, policy TiePolicy, minContigLength int, doubleStranded bool) *Assembly {
+	assembly := NewAssembly(reads)
+	// with reads from both strands, we assemble every read alongside its
+	// reverse complement, recording overlaps in a separate graph until the end
+	graph := assembly.Graph
+	if doubleStranded {
+		graph = NewAssemblyGraph()
+	}
+
+	// we keep track of the reads we've used, one read at a time (see ReadTracker),
+	// and continue for as long as some read hasn't been used.
+	used := NewReadTracker(len(reads), doubleStranded)
+	seeds := make([]int, 0) // reads waiting to start a contig (see BranchAtTie)
+	currentReadIndex, ok := used.Next()
+	for ok {
+		used.Use(currentReadIndex)
+		currentRead := strandReads[currentReadIndex].Sequence
+
+		//extend currentRead to right and extend to left as far as I can.
+		contig1, rightBranches := extendContig(currentReadIndex, find, strandReads, indexLength, verify, policy, used, graph, false)
+		contig2, leftBranches := extendContig(currentReadIndex, find, strandReads, indexLength, verify, policy, used, graph, true)
+		if policy == BranchAtTie {
+			// every way out of a repeat we stopped at gets a contig of its own
+			seeds = append(append(seeds, rightBranches...), leftBranches...)
+		}
+
+		// join into one contig
+		contig := JoinContigs(contig2, contig1, len(currentRead))
+		if doubleStranded {
+			contig = foldContig(contig, len(reads))
+		}
+
+		//previously, we appended every contig we found, even if it wasn't good (i.e., short).
+		//because coverage is high, let's just keep longer contigs, and set the rest aside.
+		if assembly.addContig(contig, minContigLength) {
+			fmt.Println("We have generated", len(assembly.Contigs), "contigs.")
+			fmt.Println("There are", used.NumUnused(), "reads left to place.")
+		}
+
+		// we need a new starting point (currentRead) if there are still reads left
+		currentReadIndex, seeds, ok = nextSeed(seeds, used)
+	}
+	sort.Ints(assembly.Unplaced)
+
+	if doubleStranded {
+		foldGraph(graph, len(reads), assembly.Graph)
+	}
+	return assembly
+}
@@ -0,0 +1,140 @@
+package assembly
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+//Contig is an assembled sequence along with the reads that built it.
+//Reads holds indices into the read collection given to the assembler,
+//in the order the reads appear along the contig from left to right.
+//Contained[i] is true if Reads[i] lies inside the contig without extending
+//it; such a read is listed right after the read that extended the contig
+//where it lies, and Contained is nil when there are none. Overlaps[i] is the
+//overlap length between the i-th and (i+1)-th of the other reads, the ones
+//that extend the contig.
+//Reverse[i] is true if Reads[i] appears in the contig reverse complemented;
+//it is nil when every read is used as is (e.g., single-stranded assembly).
+//ID is empty until the contigs are named (see NameContigs).
+type Contig struct {
+	ID        string
+	Sequence  string
+	Reads     []int
+	Overlaps  []int
+	Reverse   []bool
+	Contained []bool
+}
+
+//NewContig starts a contig consisting of a single read.
+func NewContig(readIndex int, sequence string) Contig {
+	return Contig{
+		Sequence: sequence,
+		Reads:    []int{readIndex},
+		Overlaps: make([]int, 0),
+	}
+}
+
+//AppendRead extends a contig to the right with a read whose first overlap
+//symbols overlap the end of the contig.
+func (contig *Contig) AppendRead(readIndex int, sequence string, overlap int) {
+	contig.Sequence += sequence[overlap:]
+	contig.Reads = append(contig.Reads, readIndex)
+	contig.Overlaps = append(contig.Overlaps, overlap)
+	if contig.Contained != nil {
+		contig.Contained = append(contig.Contained, false)
+	}
+}
+
+//PrependRead extends a contig to the left with a read whose last overlap
+//symbols overlap the start of the contig.
+func (contig *Contig) PrependRead(readIndex int, sequence string, overlap int) {
+	contig.Sequence = sequence[:len(sequence)-overlap] + contig.Sequence
+	contig.Reads = append([]int{readIndex}, contig.Reads...)
+	contig.Overlaps = append([]int{overlap}, contig.Overlaps...)
+	if contig.Contained != nil {
+		contig.Contained = append([]bool{false}, contig.Contained...)
+	}
+}
+
+//AddContainedRead records a read lying inside the contig. It goes right after
+//the last read, or, if atStart is true, right after the first read, whichever
+//end the contig is being extended at.
+func (contig *Contig) AddContainedRead(readIndex int, atStart bool) {
+	if contig.Contained == nil {
+		contig.Contained = make([]bool, len(contig.Reads))
+	}
+	position := len(contig.Reads)
+	if atStart {
+		position = 1
+	}
+	contig.Reads = append(contig.Reads[:position], append([]int{readIndex}, contig.Reads[position:]...)...)
+	contig.Contained = append(contig.Contained[:position], append([]bool{true}, contig.Contained[position:]...)...)
+}
+
+//IsReverse reports whether the i-th read of the contig (Reads[i]) appears in
+//it reverse complemented.
+func (contig Contig) IsReverse(i int) bool {
+	return contig.Reverse != nil && contig.Reverse[i]
+}
+
+//IsContained reports whether the i-th read of the contig (Reads[i]) lies inside
+//it without extending it.
+func (contig Contig) IsContained(i int) bool {
+	return contig.Contained != nil && contig.Contained[i]
+}
+
+//ContigSequences takes a collection of contigs and returns their sequences.
+func ContigSequences(contigs []Contig) []string {
+	sequences := make([]string, len(contigs))
+	for i := range contigs {
+		sequences[i] = contigs[i].Sequence
+	}
+	return sequences
+}
+
+//SupportingReads takes a contig and the read collection it was built from.
+//It returns the number of observed reads behind the contig, counting duplicates.
+func SupportingReads(contig Contig, reads []seqio.Read) int {
+	count := 0
+	for _, i := range contig.Reads {
+		count += reads[i].Multiplicity
+	}
+	return count
+}
+
+//EstimateCoverage takes a contig and the read collection it was built from.
+//It returns the average depth of the contig: total read bases behind it
+//(duplicates included) divided by its length.
+func EstimateCoverage(contig Contig, reads []seqio.Read) float64 {
+	if len(contig.Sequence) == 0 {
+		return 0.0
+	}
+	bases := 0
+	for _, i := range contig.Reads {
+		bases += len(reads[i].Sequence) * reads[i].Multiplicity
+	}
+	return float64(bases) / float64(len(contig.Sequence))
+}
+
+//ContigID returns the name of the contig at position i (counting from 0).
+func ContigID(i int) string {
+	return "contig_" + strconv.Itoa(i+1)
+}
+
+//NameContigs sorts contigs from longest to shortest (breaking ties by sequence)
+//and names them contig_1, contig_2, ... in that order. The assemblers find
+//contigs in whatever order they happen to hit the reads, so this gives the same
+//IDs for the same assembly from run to run.
+func NameContigs(contigs []Contig) {
+	sort.SliceStable(contigs, func(i, j int) bool {
+		if len(contigs[i].Sequence) != len(contigs[j].Sequence) {
+			return len(contigs[i].Sequence) > len(contigs[j].Sequence)
+		}
+		return contigs[i].Sequence < contigs[j].Sequence
+	})
+	for i := range contigs {
+		contigs[i].ID = ContigID(i)
+	}
+}
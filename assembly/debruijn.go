@@ -0,0 +1,466 @@
+package assembly
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/packed"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// part 5: de Bruijn graphs
+// GenomeAssembler2 already glued k-mers together along (k-1)-overlaps, but it
+// walked whichever match it found first. Here we build the whole graph instead:
+// every (k-1)-mer is a node and every k-mer is an edge from its prefix to its
+// suffix. We never compare reads against each other, we just count k-mers,
+// which is why this is the way to go for lots of short reads at high coverage.
+
+//DeBruijnGraph is the de Bruijn graph of a collection of reads. Nodes are
+//(k-1)-mers and every k-mer is an edge from its first k-1 symbols to its last
+//k-1 symbols. Counts holds how many times each k-mer was seen across the reads,
+//packed into a number (see packed.Kmer); k-mers longer than
+//packed.MaxKmerLength are packed into several words and counted in LongCounts
+//instead (see packed.LongKmer).
+type DeBruijnGraph struct {
+	K          int
+	Counts     map[packed.Kmer]int
+	LongCounts map[packed.LongKmer]int
+}
+
+//Unitig is a maximal non-branching path through a de Bruijn graph, spelled out
+//as a string. Coverage is the average count of the k-mers along it.
+type Unitig struct {
+	Sequence string
+	Coverage float64
+}
+
+//BuildDeBruijnGraph takes a collection of reads and counts their k-mers with
+//kmer.CountReadKmers, or kmer.CountReadLongKmers for long k-mers (each read
+//counts as many times as its multiplicity). K-mers seen fewer than
+//minKmerCount times are thrown out: at high coverage, a k-mer that shows up
+//once or twice is almost always a sequencing error. Reads shorter than k or
+//with symbols other than A, C, G, T are skipped. If doubleStranded is true,
+//every read is counted on both strands (as if its reverse complement were a
+//read too), so a k-mer and its reverse complement always have the same count.
+func BuildDeBruijnGraph(reads []seqio.Read, k, minKmerCount int, doubleStranded bool) (*DeBruijnGraph, error) {
+	if len(reads) == 0 {
+		return nil, fmt.Errorf("BuildDeBruijnGraph: %w", walker.ErrNoReads)
+	}
+	if k < 2 {
+		return nil, &walker.ParameterError{Name: "k", Value: k, Reason: "must be at least 2"}
+	}
+	if minKmerCount < 1 {
+		return nil, &walker.ParameterError{Name: "minKmerCount", Value: minKmerCount, Reason: "must be at least 1"}
+	}
+
+	graph := &DeBruijnGraph{K: k}
+	var err error
+	if k <= packed.MaxKmerLength {
+		graph.Counts, err = kmer.CountReadKmers(reads, k, doubleStranded)
+	} else {
+		graph.LongCounts, err = kmer.CountReadLongKmers(reads, k, doubleStranded)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// deleting from a map while ranging over it is allowed in Go, and only
+	// one of the two maps has anything in it
+	for kmer, count := range graph.Counts {
+		if count < minKmerCount {
+			delete(graph.Counts, kmer)
+		}
+	}
+	for kmer, count := range graph.LongCounts {
+		if count < minKmerCount {
+			delete(graph.LongCounts, kmer)
+		}
+	}
+	return graph, nil
+}
+
+//NumKmers returns the number of k-mers (edges) in the graph.
+func (graph *DeBruijnGraph) NumKmers() int {
+	return len(graph.Counts) + len(graph.LongCounts)
+}
+
+//Count returns how many times a k-mer was seen, or 0 if it isn't in the graph.
+func (graph *DeBruijnGraph) Count(pattern string) int {
+	if graph.K > packed.MaxKmerLength {
+		kmer, ok := packed.EncodeLongKmer(pattern)
+		if !ok {
+			return 0
+		}
+		return graph.LongCounts[kmer]
+	}
+	kmer, ok := packed.EncodeKmer(pattern)
+	if !ok {
+		return 0
+	}
+	return graph.Counts[kmer]
+}
+
+//Successors returns the k-mers (edges) leaving a (k-1)-mer node.
+func (graph *DeBruijnGraph) Successors(node string) []string {
+	edges := make([]string, 0, 4)
+	for _, symbol := range "ACGT" {
+		edge := node + string(symbol)
+		if graph.Count(edge) > 0 {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+//Predecessors returns the k-mers (edges) entering a (k-1)-mer node.
+func (graph *DeBruijnGraph) Predecessors(node string) []string {
+	edges := make([]string, 0, 4)
+	for _, symbol := range "ACGT" {
+		edge := string(symbol) + node
+		if graph.Count(edge) > 0 {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+//Unitigs compacts the graph: every maximal path whose inner nodes don't
+//branch becomes one unitig, and every edge ends up in exactly one unitig.
+//Unitigs come out in a fixed order (by their first k-mer), so the same reads
+//always give the same unitigs.
+func (graph *DeBruijnGraph) Unitigs() []Unitig {
+	if graph.K > packed.MaxKmerLength {
+		return compactUnitigs[packed.LongKmer](&longKmerGraph{k: graph.K, counts: graph.LongCounts})
+	}
+	return compactUnitigs[packed.Kmer](&shortKmerGraph{k: graph.K, counts: graph.Counts})
+}
+
+// compacting unitigs walks from edge to edge millions of times, so it works on
+// packed k-mers rather than strings. They are packed one way when k is at most
+// packed.MaxKmerLength and another way when it's longer, but the walk is the
+// same either way, so it is written once for any packedGraph.
+
+//packedKmer is either way of packing a k-mer. Both sort like the strings
+//they stand for, as long as they are all the same length.
+type packedKmer interface {
+	packed.Kmer | packed.LongKmer
+}
+
+//packedGraph is a de Bruijn graph whose k-mers (edges) and (k-1)-mers (nodes)
+//are packed as K.
+type packedGraph[K packedKmer] interface {
+	kmers() []K
+	count(edge K) int
+	successors(node K) []K
+	predecessors(node K) []K
+	prefix(edge K) K // the node an edge leaves: its first k-1 symbols
+	suffix(edge K) K // the node an edge enters: its last k-1 symbols
+	lastSymbol(edge K) byte
+	spell(edge K) string
+}
+
+//nonBranching is true if a node has exactly one edge in and one edge out,
+//so a unitig passing through it can keep going.
+func nonBranching[K packedKmer](graph packedGraph[K], node K) bool {
+	return len(graph.predecessors(node)) == 1 && len(graph.successors(node)) == 1
+}
+
+//compactUnitigs is DeBruijnGraph.Unitigs for either way of packing k-mers.
+func compactUnitigs[K packedKmer](graph packedGraph[K]) []Unitig {
+	kmers := graph.kmers()
+	sort.Slice(kmers, func(i, j int) bool { return kmers[i] < kmers[j] })
+
+	used := make(map[K]bool)
+	unitigs := make([]Unitig, 0)
+
+	// first, start a unitig at every edge leaving a branching node (or a
+	// node with nothing coming in)
+	for _, kmer := range kmers {
+		if !used[kmer] && !nonBranching(graph, graph.prefix(kmer)) {
+			unitigs = append(unitigs, walkUnitig(graph, kmer, used))
+		}
+	}
+	// whatever is left lies on cycles where no node branches, e.g., a
+	// circular plasmid; cut each cycle open wherever we happen to be
+	for _, kmer := range kmers {
+		if !used[kmer] {
+			unitigs = append(unitigs, walkUnitig(graph, kmer, used))
+		}
+	}
+	return unitigs
+}
+
+//walkUnitig follows the graph from a starting edge for as long as it doesn't
+//branch, marking the edges it takes as used.
+func walkUnitig[K packedKmer](graph packedGraph[K], start K, used map[K]bool) Unitig {
+	sequence := []byte(graph.spell(start))
+	used[start] = true
+	total := graph.count(start)
+	numKmers := 1
+
+	node := graph.suffix(start)
+	for nonBranching(graph, node) {
+		next := graph.successors(node)[0]
+		if used[next] {
+			// back to where we started on a cycle
+			break
+		}
+		used[next] = true
+		total += graph.count(next)
+		numKmers++
+		sequence = append(sequence, graph.lastSymbol(next))
+		node = graph.suffix(next)
+	}
+	return Unitig{
+		Sequence: string(sequence),
+		Coverage: float64(total) / float64(numKmers),
+	}
+}
+
+//shortKmerGraph is a packedGraph with k-mers packed into a number. Appending a
+//symbol c to a node is node<<2|c, putting it in front is c<<(2(k-1))|node, the
+//prefix of an edge is edge>>2 and its suffix is edge with its first symbol
+//masked off.
+type shortKmerGraph struct {
+	k      int
+	counts map[packed.Kmer]int
+}
+
+func (graph *shortKmerGraph) kmers() []packed.Kmer {
+	kmers := make([]packed.Kmer, 0, len(graph.counts))
+	for kmer := range graph.counts {
+		kmers = append(kmers, kmer)
+	}
+	return kmers
+}
+
+func (graph *shortKmerGraph) count(edge packed.Kmer) int {
+	return graph.counts[edge]
+}
+
+func (graph *shortKmerGraph) successors(node packed.Kmer) []packed.Kmer {
+	edges := make([]packed.Kmer, 0, 4)
+	for c := packed.Kmer(0); c < 4; c++ {
+		if edge := node<<2 | c; graph.counts[edge] > 0 {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+func (graph *shortKmerGraph) predecessors(node packed.Kmer) []packed.Kmer {
+	edges := make([]packed.Kmer, 0, 4)
+	for c := packed.Kmer(0); c < 4; c++ {
+		if edge := c<<(2*(graph.k-1)) | node; graph.counts[edge] > 0 {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+func (graph *shortKmerGraph) prefix(edge packed.Kmer) packed.Kmer {
+	return edge >> 2
+}
+
+func (graph *shortKmerGraph) suffix(edge packed.Kmer) packed.Kmer {
+	return edge & (1<<(2*(graph.k-1)) - 1)
+}
+
+func (graph *shortKmerGraph) lastSymbol(edge packed.Kmer) byte {
+	// the last symbol is in the last two bits
+	return "ACGT"[edge&3]
+}
+
+func (graph *shortKmerGraph) spell(edge packed.Kmer) string {
+	return edge.Decode(graph.k)
+}
+
+//longKmerGraph is a packedGraph with k-mers packed into several words. Moving
+//symbols between words is fiddly, so it spells k-mers out to step from one to
+//the next, which takes time proportional to k; at such a k, that is still
+//much less than the reads took to count.
+type longKmerGraph struct {
+	k      int
+	counts map[packed.LongKmer]int
+}
+
+func (graph *longKmerGraph) kmers() []packed.LongKmer {
+	kmers := make([]packed.LongKmer, 0, len(graph.counts))
+	for kmer := range graph.counts {
+		kmers = append(kmers, kmer)
+	}
+	return kmers
+}
+
+func (graph *longKmerGraph) count(edge packed.LongKmer) int {
+	return graph.counts[edge]
+}
+
+//pack packs a pattern that we spelled out ourselves, so it is all A, C, G, T.
+func (graph *longKmerGraph) pack(pattern string) packed.LongKmer {
+	kmer, _ := packed.EncodeLongKmer(pattern)
+	return kmer
+}
+
+func (graph *longKmerGraph) successors(node packed.LongKmer) []packed.LongKmer {
+	spelled := node.Decode(graph.k - 1)
+	edges := make([]packed.LongKmer, 0, 4)
+	for _, symbol := range "ACGT" {
+		if edge := graph.pack(spelled + string(symbol)); graph.counts[edge] > 0 {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+func (graph *longKmerGraph) predecessors(node packed.LongKmer) []packed.LongKmer {
+	spelled := node.Decode(graph.k - 1)
+	edges := make([]packed.LongKmer, 0, 4)
+	for _, symbol := range "ACGT" {
+		if edge := graph.pack(string(symbol) + spelled); graph.counts[edge] > 0 {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+func (graph *longKmerGraph) prefix(edge packed.LongKmer) packed.LongKmer {
+	return graph.pack(edge.Decode(graph.k)[:graph.k-1])
+}
+
+func (graph *longKmerGraph) suffix(edge packed.LongKmer) packed.LongKmer {
+	return graph.pack(edge.Decode(graph.k)[1:])
+}
+
+func (graph *longKmerGraph) lastSymbol(edge packed.LongKmer) byte {
+	return edge.Decode(graph.k)[graph.k-1]
+}
+
+func (graph *longKmerGraph) spell(edge packed.LongKmer) string {
+	return edge.Decode(graph.k)
+}
+
+//GenomeAssemblerDeBruijn assembles reads by building their de Bruijn graph
+//(see BuildDeBruijnGraph) and compacting it into unitigs, one contig per unitig.
+//Since contigs come from k-mers rather than from whole reads, the assembly's
+//Reads are the unitigs themselves: unitig_1, unitig_2, ..., each with its
+//k-mer coverage (rounded) as its multiplicity. The graph links unitig A to
+//unitig B when B can follow A, overlapping it by k-1 symbols.
+//
+//If doubleStranded is true, the graph has both strands of every read, so its
+//unitigs come in pairs: a unitig and its reverse complement. We only keep the
+//one that comes first alphabetically, and links to the other one become links
+//to the reverse complement of the one we kept.
+func GenomeAssemblerDeBruijn(reads []seqio.Read, k, minKmerCount int, doubleStranded bool) (*Assembly, error) {
+	fmt.Println("Counting k-mers.")
+	graph, err := BuildDeBruijnGraph(reads, k, minKmerCount, doubleStranded)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("De Bruijn graph built with", graph.NumKmers(), "k-mers. Compacting unitigs.")
+	allUnitigs := graph.Unitigs()
+
+	// kept[i] is the position among the unitigs we keep of unitig i or of its
+	// reverse complement, and reverse[i] tells which
+	kept := make([]int, len(allUnitigs))
+	reverse := make([]bool, len(allUnitigs))
+	unitigs := make([]Unitig, 0, len(allUnitigs))
+	if !doubleStranded {
+		for i := range allUnitigs {
+			kept[i] = i
+		}
+		unitigs = allUnitigs
+	} else {
+		partner := pairStrands(allUnitigs, k)
+		for i, unitig := range allUnitigs {
+			j := partner[i]
+			if j < i {
+				// we already kept its partner
+				kept[i], reverse[i] = kept[j], !reverse[j]
+				continue
+			}
+			kept[i] = len(unitigs)
+			rc := kmer.ReverseComplement(unitig.Sequence)
+			if rc < unitig.Sequence {
+				unitig.Sequence = rc
+				reverse[i] = true
+			}
+			unitigs = append(unitigs, unitig)
+		}
+	}
+
+	unitigReads := make([]seqio.Read, len(unitigs))
+	for i, unitig := range unitigs {
+		unitigReads[i] = seqio.Read{
+			ID:           "unitig_" + strconv.Itoa(i+1),
+			Sequence:     unitig.Sequence,
+			Multiplicity: int(unitig.Coverage + 0.5),
+		}
+	}
+	assembly := NewAssembly(unitigReads)
+
+	// unitigs meet at branching nodes: the last k-1 symbols of one are the
+	// first k-1 symbols of every unitig that can follow it
+	starts := make(map[string][]int)
+	for i, unitig := range allUnitigs {
+		node := unitig.Sequence[:k-1]
+		starts[node] = append(starts[node], i)
+	}
+	for i, unitig := range allUnitigs {
+		node := unitig.Sequence[len(unitig.Sequence)-k+1:]
+		for _, j := range starts[node] {
+			assembly.Graph.AddOrientedLink(kept[i], reverse[i], kept[j], reverse[j], k-1)
+		}
+	}
+
+	for i, unitig := range unitigs {
+		assembly.Contigs = append(assembly.Contigs, NewContig(i, unitig.Sequence))
+	}
+	fmt.Println("Found", len(unitigs), "unitigs.")
+	return assembly, nil
+}
+
+//pairStrands takes the unitigs of a double-stranded de Bruijn graph and
+//returns, for each one, the position of its reverse complement (which may be
+//itself). That is usually just another unitig, but cycles are cut open wherever
+//Unitigs happened to start, so the two strands of a cycle may be cut in
+//different places; we find those by looking up the k-mers they contain.
+func pairStrands(unitigs []Unitig, k int) []int {
+	partner := make([]int, len(unitigs))
+	position := make(map[string]int)
+	for i, unitig := range unitigs {
+		position[unitig.Sequence] = i
+	}
+
+	unpaired := make([]int, 0)
+	for i, unitig := range unitigs {
+		j, found := position[kmer.ReverseComplement(unitig.Sequence)]
+		if found {
+			partner[i] = j
+		} else {
+			unpaired = append(unpaired, i)
+		}
+	}
+
+	// k may be too long for a packed.Kmer, so these are packed any length
+	cycleKmers := make(map[packed.LongKmer]int) // k-mer -> unpaired unitig containing it
+	for _, i := range unpaired {
+		// unitigs are spelled with A, C, G, T only and k is positive
+		kmers, _ := packed.NewLongKmerIterator(unitigs[i].Sequence, k)
+		for kmers.Next() {
+			cycleKmers[kmers.Kmer()] = i
+		}
+	}
+	for _, i := range unpaired {
+		rc, _ := packed.EncodeLongKmer(kmer.ReverseComplement(unitigs[i].Sequence[:k]))
+		j, found := cycleKmers[rc]
+		if !found {
+			j = i
+		}
+		partner[i] = j
+	}
+	return partner
+}
@@ -0,0 +1,9 @@
+//Package assembly turns reads into contigs.
+//
+//GenomeAssembler1 and GenomeAssembler2 reconstruct a genome from a perfect
+//k-mer composition. GenomeAssembler3 (exact overlaps) and GenomeAssembler4
+//(error-tolerant overlaps) greedily extend contigs read by read using prefix
+//and suffix indices, and return an Assembly holding the contigs, the reads
+//they came from and the overlap graph they walked. Contigs can be written as
+//FASTA (WriteContigsFASTA) and the graph as GFA (WriteGFA).
+package assembly
@@ -0,0 +1,263 @@
+package assembly
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// GenomeAssembler1 and GenomeAssembler2 always take the first k-mer that fits,
+// which goes wrong as soon as a (k-1)-mer repeats: the genome may need the
+// other k-mer first. The fix is to think of the k-mers as edges of a de Bruijn
+// graph, where a genome with this k-mer composition is exactly a path using every
+// edge once, i.e., an Eulerian path. Hierholzer's algorithm finds one in linear
+// time, and backtracking over the same graph finds all of them.
+
+//ErrAmbiguousReconstruction means more than one genome has the given k-mer
+//composition, so we can't say which one the k-mers came from.
+var ErrAmbiguousReconstruction = errors.New("k-mer composition has more than one reconstruction")
+
+//ErrReconstructionUndetermined means the search for reconstructions gave up
+//(see maxStepsPerKmer) before it could tell how many there are.
+var ErrReconstructionUndetermined = errors.New("gave up looking for the reconstructions of the k-mer composition")
+
+//maxStepsPerKmer bounds the backtracking search of EulerianReconstructions: it
+//gives up after this many steps per k-mer. A wrong turn at a repeat is only
+//found out when the walk gets stuck, possibly much later, and wrong turns at
+//repeats within that stretch multiply, so the search can take exponentially
+//long even when only a few reconstructions exist.
+const maxStepsPerKmer = 100
+
+//kmerGraph is the de Bruijn graph of a k-mer composition, keeping every copy
+//of a repeated k-mer. It is built by newKmerGraph.
+type kmerGraph struct {
+	k        int
+	numEdges int
+	// out[node] lists the nodes node has an edge to, one entry per k-mer,
+	// sorted so that walks are the same from run to run
+	out map[string][]string
+	// edges[kmer] is how many copies of kmer we have
+	edges map[string]int
+	// start is where every Eulerian path must begin, or "" if the graph is
+	// balanced and a path may begin anywhere (it will then be a cycle)
+	start string
+}
+
+//newKmerGraph builds the graph of a k-mer composition and checks the degree
+//conditions for an Eulerian path: at most one node with one more edge out than
+//in (the start), at most one with one more in than out (the end), and every
+//other node balanced.
+func newKmerGraph(kmers []string) (*kmerGraph, error) {
+	k, err := CheckKmers(kmers)
+	if err != nil {
+		return nil, err
+	}
+	graph := &kmerGraph{
+		k:        k,
+		numEdges: len(kmers),
+		out:      make(map[string][]string),
+		edges:    make(map[string]int),
+	}
+	balance := make(map[string]int) // out-degree minus in-degree
+	for _, kmer := range kmers {
+		from, to := kmer[:k-1], kmer[1:]
+		graph.out[from] = append(graph.out[from], to)
+		graph.edges[kmer]++
+		balance[from]++
+		balance[to]--
+	}
+	for node := range graph.out {
+		sort.Strings(graph.out[node])
+	}
+
+	starts, ends := 0, 0
+	for node, b := range balance {
+		switch {
+		case b == 1:
+			starts++
+			graph.start = node
+		case b == -1:
+			ends++
+		case b != 0:
+			return nil, fmt.Errorf("%w: (k-1)-mer %s has %d more edges out than in, so no Eulerian path exists", ErrAssemblyStuck, node, b)
+		}
+	}
+	if starts != ends || starts > 1 {
+		return nil, fmt.Errorf("%w: %d (k-1)-mers need to start a path and %d need to end one, so no Eulerian path exists", ErrAssemblyStuck, starts, ends)
+	}
+	return graph, nil
+}
+
+//startNodes returns the nodes an Eulerian path may start from.
+func (graph *kmerGraph) startNodes() []string {
+	if graph.start != "" {
+		return []string{graph.start}
+	}
+	nodes := make([]string, 0, len(graph.out))
+	for node := range graph.out {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+//GenomeAssemblerEulerian takes a k-mer composition (as KmerComposition produces
+//it, repeats included) and returns the genome it came from, found as an Eulerian
+//path with Hierholzer's algorithm. Unlike GenomeAssembler1 and GenomeAssembler2
+//it never takes a wrong turn at a repeat. If no genome has this composition it
+//returns ErrAssemblyStuck; if more than one does (see EulerianReconstructions)
+//it returns ErrAmbiguousReconstruction rather than guessing, and if the search
+//for a second one gives up, ErrReconstructionUndetermined.
+func GenomeAssemblerEulerian(kmers []string) (string, error) {
+	graph, err := newKmerGraph(kmers)
+	if err != nil {
+		return "", err
+	}
+	genome, err := graph.hierholzer(graph.startNodes()[0])
+	if err != nil {
+		return "", err
+	}
+	reconstructions, err := EulerianReconstructions(kmers, 2)
+	if len(reconstructions) > 1 {
+		return "", fmt.Errorf("%w: e.g., genomes starting %s... and %s...", ErrAmbiguousReconstruction, abbreviate(reconstructions[0]), abbreviate(reconstructions[1]))
+	}
+	if err != nil {
+		return "", err
+	}
+	return genome, nil
+}
+
+//abbreviate shortens a genome for error messages.
+func abbreviate(genome string) string {
+	if len(genome) > 20 {
+		return genome[:20]
+	}
+	return genome
+}
+
+//hierholzer finds an Eulerian path from start: walk until stuck, and whenever
+//we get stuck, back up to the last node with unused edges and splice in a
+//detour from there. The nodes come off the stack in reverse order.
+func (graph *kmerGraph) hierholzer(start string) (string, error) {
+	next := make(map[string]int) // next unused position in out[node]
+	stack := []string{start}
+	path := make([]string, 0, graph.numEdges+1)
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		if next[node] < len(graph.out[node]) {
+			stack = append(stack, graph.out[node][next[node]])
+			next[node]++
+		} else {
+			path = append(path, node)
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(path) != graph.numEdges+1 {
+		// the balanced degrees fooled us: some edges are out of reach
+		return "", fmt.Errorf("%w: the k-mers form more than one connected piece, so no Eulerian path exists", ErrAssemblyStuck)
+	}
+
+	genome := make([]byte, 0, graph.numEdges+graph.k-1)
+	genome = append(genome, path[len(path)-1]...)
+	for i := len(path) - 2; i >= 0; i-- {
+		genome = append(genome, path[i][graph.k-2])
+	}
+	return string(genome), nil
+}
+
+//EulerianReconstructions takes a k-mer composition (repeats included) and
+//returns every genome whose k-mer composition it is, in alphabetical order,
+//by backtracking through all Eulerian paths. Copies of the same k-mer are
+//interchangeable, so each genome is listed once. There can be exponentially
+//many, so at most limit are returned (limit <= 0 means no limit). If the
+//k-mers have no Eulerian path at all, it returns ErrAssemblyStuck. The search
+//itself can take exponentially long, so it gives up after maxStepsPerKmer
+//steps per k-mer, returning the genomes found so far along with
+//ErrReconstructionUndetermined.
+func EulerianReconstructions(kmers []string, limit int) ([]string, error) {
+	graph, err := newKmerGraph(kmers)
+	if err != nil {
+		return nil, err
+	}
+	// make sure a path exists before we go looking for all of them
+	_, err = graph.hierholzer(graph.startNodes()[0])
+	if err != nil {
+		return nil, err
+	}
+
+	reconstructions := make([]string, 0)
+	remaining := make(map[string]int)
+	for kmer, count := range graph.edges {
+		remaining[kmer] = count
+	}
+	budget := maxStepsPerKmer * graph.numEdges
+	for _, start := range graph.startNodes() {
+		done, err := graph.backtrack(start, remaining, limit, &budget, &reconstructions)
+		if err != nil {
+			return reconstructions, err
+		}
+		if done {
+			break
+		}
+	}
+	return reconstructions, nil
+}
+
+//step is one level of the backtracking search: the node we are at and the
+//position in out[node] of the next edge to try.
+type step struct {
+	node string
+	next int
+}
+
+//backtrack tries every way of extending a genome from start, one unused k-mer
+//at a time, and records the genomes that use up all of the k-mers. It keeps
+//its own stack rather than recursing, since a genome of millions of symbols
+//would mean millions of nested calls. It returns true once limit
+//reconstructions have been found, so the search can stop, and
+//ErrReconstructionUndetermined if it takes more steps than budget has left.
+func (graph *kmerGraph) backtrack(start string, remaining map[string]int, limit int, budget *int, reconstructions *[]string) (bool, error) {
+	k := graph.k
+	genome := []byte(start)
+	stack := []step{{node: start}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		targets := graph.out[top.node]
+		if top.next == len(targets) {
+			// nothing left to try here, so give back the k-mer that got us here
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				remaining[string(genome[len(genome)-k:])]++
+				genome = genome[:len(genome)-1]
+			}
+			continue
+		}
+
+		if *budget == 0 {
+			return false, fmt.Errorf("%w: found %d after %d steps per k-mer", ErrReconstructionUndetermined, len(*reconstructions), maxStepsPerKmer)
+		}
+		*budget--
+
+		i := top.next
+		top.next++
+		if i > 0 && targets[i] == targets[i-1] {
+			// another copy of a k-mer we just tried
+			continue
+		}
+		kmer := top.node + targets[i][k-2:]
+		if remaining[kmer] == 0 {
+			continue
+		}
+		remaining[kmer]--
+		genome = append(genome, kmer[k-1])
+		stack = append(stack, step{node: kmer[1:]})
+
+		if len(genome)-(k-1) == graph.numEdges {
+			*reconstructions = append(*reconstructions, string(genome))
+			if limit > 0 && len(*reconstructions) >= limit {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
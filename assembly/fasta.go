@@ -0,0 +1,95 @@
+package assembly
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+//WriteContigsToFile writes contigs to a FASTA file. See WriteContigsFASTA for the format.
+func WriteContigsToFile(contigs []Contig, reads []seqio.Read, outFilename string, lineWidth int) error {
+	outFile, err := os.Create(outFilename)
+	if err != nil {
+		return err
+	}
+	err = WriteContigsFASTA(outFile, contigs, reads, lineWidth)
+	closeErr := outFile.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+//WriteContigsFASTA writes contigs as FASTA records. Each header carries the
+//contig ID (contig_1, contig_2, ... by position if the contig has none) and its
+//length; if reads (the collection the contigs were assembled from) is not nil,
+//it also carries the estimated coverage and the number of supporting reads:
+//
+//	>contig_1 len=104233 cov=31.72 reads=4410
+//
+//Sequence lines are wrapped every lineWidth symbols; lineWidth <= 0 puts each
+//sequence on one line.
+func WriteContigsFASTA(w io.Writer, contigs []Contig, reads []seqio.Read, lineWidth int) error {
+	out := bufio.NewWriter(w)
+	for i, contig := range contigs {
+		id := contig.ID
+		if id == "" {
+			id = ContigID(i)
+		}
+		description := fmt.Sprintf("len=%d", len(contig.Sequence))
+		if reads != nil {
+			description += fmt.Sprintf(" cov=%.2f reads=%d", EstimateCoverage(contig, reads), SupportingReads(contig, reads))
+		}
+		seqio.WriteFASTA(out, id, description, contig.Sequence, lineWidth)
+	}
+	// bufio.Writer remembers the first write error, so one check covers everything
+	return out.Flush()
+}
+
+//WriteUnplacedToFile writes what an assembly set aside to a FASTA file. See
+//WriteUnplacedFASTA for the format.
+func WriteUnplacedToFile(assembly *Assembly, outFilename string, lineWidth int) error {
+	outFile, err := os.Create(outFilename)
+	if err != nil {
+		return err
+	}
+	err = WriteUnplacedFASTA(outFile, assembly, lineWidth)
+	closeErr := outFile.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+//WriteUnplacedFASTA writes the short contigs of an assembly, then its unplaced
+//reads, as FASTA records. Short contigs are named short_1, short_2, ... by
+//position (unless they have an ID) and described like contigs (see
+//WriteContigsFASTA); unplaced reads keep their IDs (read_1, read_2, ... by index
+//if they have none) and carry their length:
+//
+//	>short_1 len=8420 cov=18.03 reads=61
+//	>read_4411 len=1712 unplaced
+func WriteUnplacedFASTA(w io.Writer, assembly *Assembly, lineWidth int) error {
+	out := bufio.NewWriter(w)
+	for i, contig := range assembly.ShortContigs {
+		id := contig.ID
+		if id == "" {
+			id = "short_" + strconv.Itoa(i+1)
+		}
+		description := fmt.Sprintf("len=%d cov=%.2f reads=%d", len(contig.Sequence), EstimateCoverage(contig, assembly.Reads), SupportingReads(contig, assembly.Reads))
+		seqio.WriteFASTA(out, id, description, contig.Sequence, lineWidth)
+	}
+	for _, i := range assembly.Unplaced {
+		read := assembly.Reads[i]
+		id := read.ID
+		if id == "" {
+			id = "read_" + strconv.Itoa(i+1)
+		}
+		seqio.WriteFASTA(out, id, fmt.Sprintf("len=%d unplaced", len(read.Sequence)), read.Sequence, lineWidth)
+	}
+	return out.Flush()
+}
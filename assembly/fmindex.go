@@ -0,0 +1,122 @@
+package assembly
+
+import (
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/fmindex"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// GenomeAssembler3 finds exact overlaps by looking up indexLength symbols at
+// every position of the current read in the prefix index and then comparing
+// the whole overlap. An FM-index of the reads (see fmindex.ReadIndex) answers
+// the question directly: one backward search over the current read lists every
+// read that starts with a suffix of it, with the longest such suffix. An index
+// of the reversed reads does the same for reads ending with a prefix of it. With
+// double-stranded reads we can do without that second index: a read ends with a
+// prefix of the current read exactly when its reverse complement (which is also
+// a read) starts with a suffix of the current read's reverse complement.
+
+//fmCandidateFinder returns a candidateFinder for exact overlaps of at least
+//minMatchLength symbols, found in forward, an FM-index of the reads, or in
+//backward, an FM-index of the reversed reads, when extending left. If backward
+//is nil, reads must come from DoubleStrandedReads and left overlaps are found
+//through reverse complements instead. inside[i] lists the reads lying inside
+//read i.
+func fmCandidateFinder(forward, backward *fmindex.ReadIndex, inside [][]int, reads []seqio.Read, minMatchLength int) candidateFinder {
+	return func(currentReadIndex int, currentRead string, used *ReadTracker, withPlaced, left bool) ([]OverlapCandidate, []int) {
+		candidates := make([]OverlapCandidate, 0)
+		contained := make([]int, 0)
+		for _, i := range inside[currentReadIndex] {
+			if !used.IsUsed(i) {
+				contained = append(contained, i)
+			}
+		}
+
+		var overlaps []fmindex.ReadOverlap
+		if left && backward == nil {
+			overlaps = forward.SuffixPrefixOverlaps(kmer.ReverseComplement(currentRead), minMatchLength)
+			// the reads that start with those suffixes are the reverse complements
+			// of the ones we want
+			n := len(reads) / 2
+			for i := range overlaps {
+				overlaps[i].Read = (overlaps[i].Read + n) % len(reads)
+			}
+		} else if left {
+			overlaps = backward.SuffixPrefixOverlaps(kmer.Reverse(currentRead), minMatchLength)
+		} else {
+			overlaps = forward.SuffixPrefixOverlaps(currentRead, minMatchLength)
+		}
+		n := len(currentRead)
+		for _, overlap := range overlaps {
+			i := overlap.Read
+			// a read must stick out past the current read (if it starts, or going
+			// left ends, where the current read does, it contains it)
+			if (used.IsUsed(i) && !withPlaced) || overlap.Length >= len(reads[i].Sequence) {
+				continue
+			}
+			candidate, _, ok := checkCandidate(currentRead, i, n-overlap.Length, reads, ExactVerifier, left)
+			if ok {
+				candidate.placed = used.IsUsed(i)
+				candidates = append(candidates, candidate)
+			}
+		}
+		return candidates, contained
+	}
+}
+
+//readsInside finds, for every read, the other reads that occur in it, by
+//looking every read up in an FM-index of all of them.
+func readsInside(reads []seqio.Read, forward *fmindex.ReadIndex) [][]int {
+	inside := make([][]int, len(reads))
+	for b := range reads {
+		previous := -1
+		for _, hit := range forward.Locate(reads[b].Sequence) {
+			if hit.Read != b && hit.Read != previous {
+				inside[hit.Read] = append(inside[hit.Read], b)
+				previous = hit.Read
+			}
+		}
+	}
+	return inside
+}
+
+//GenomeAssembler3WithFMIndex is GenomeAssembler3 with the prefix and suffix
+//indices replaced by an FM-index of the reads (and, for single-stranded reads,
+//one of the reversed reads), which finds every exact overlap of at least
+//minMatchLength symbols with the current read without a lookup at every
+//position of it.
+func GenomeAssembler3WithFMIndex(reads []seqio.Read, minMatchLength, minContigLength int, policy TiePolicy, doubleStranded bool) (*Assembly, error) {
+	if len(reads) == 0 {
+		return nil, fmt.Errorf("GenomeAssembler: %w", walker.ErrNoReads)
+	}
+	if minMatchLength < 1 {
+		return nil, &walker.ParameterError{Name: "minMatchLength", Value: minMatchLength, Reason: "must be positive"}
+	}
+
+	strandReads := reads
+	if doubleStranded {
+		strandReads = DoubleStrandedReads(reads)
+	}
+
+	fmt.Println("Building an FM-index of the reads.")
+	sequences := seqio.ReadSequences(strandReads)
+	forward := fmindex.NewReadIndex(sequences)
+	var backward *fmindex.ReadIndex
+	if !doubleStranded {
+		fmt.Println("Building an FM-index of the reversed reads.")
+		reversed := make([]string, len(sequences))
+		for i, sequence := range sequences {
+			reversed[i] = kmer.Reverse(sequence)
+		}
+		backward = fmindex.NewReadIndex(reversed)
+	}
+	fmt.Println("FM-index built!")
+	inside := readsInside(strandReads, forward)
+
+	find := fmCandidateFinder(forward, backward, inside, strandReads, minMatchLength)
+	// any difference between exact extensions is a disagreement
+	return assembleWithFinder(reads, strandReads, find, 1, ExactVerifier, policy, minContigLength, doubleStranded), nil
+}
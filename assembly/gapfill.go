@@ -0,0 +1,198 @@
+package assembly
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kaushikvemparala/Walker/index"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// the reads an assembler couldn't place are often the ones that reach into the
+// places it couldn't get through, i.e., the gaps between scaffolded contigs.
+// So for every gap, we take the end of the contig on its left (the left flank)
+// and the start of the contig on its right (the right flank), treat them as
+// reads, and assemble outwards from each with the leftover reads, exactly as
+// the assemblers extend contigs. If the extension from the left flank reaches
+// the right flank, or the other way around, and the other extension agrees, we
+// know what lies in the gap.
+
+//FillGaps tries to close the open gaps of scaffolds (built from contigs) with
+//reads, e.g., the reads the assembler left unplaced. Reads are joined to the
+//flanks of each gap as in ExtendContigRightInexact and ExtendContigLeftInexact:
+//they must overlap by at least minMatchLength symbols, indexLength symbols of
+//which are looked up in the read indices, and verify must accept the overlap.
+//Extensions stop where the reads disagree (see StopAtTie). A gap is closed
+//when one extension reaches across to the other flank and the other extension
+//is consistent with it. Scaffolds are changed in place; it returns the number
+//of gaps closed.
+func FillGaps(scaffolds []Scaffold, contigs []Contig, reads []seqio.Read, minMatchLength, indexLength int, verify OverlapVerifier, doubleStranded bool) (int, error) {
+	err := CheckAssemblyParameters(reads, minMatchLength, indexLength)
+	if err != nil {
+		return 0, err
+	}
+
+	// reads too short to overlap a flank by minMatchLength are no use. The flanks
+	// are as long as the longest read, so that every read overlapping the end of
+	// a contig overlaps its flank.
+	gapReads := make([]seqio.Read, 0, len(reads))
+	flankLength := 0
+	for _, read := range reads {
+		if len(read.Sequence) >= minMatchLength {
+			gapReads = append(gapReads, read)
+			if len(read.Sequence) > flankLength {
+				flankLength = len(read.Sequence)
+			}
+		}
+	}
+	numReads := len(gapReads)
+
+	// the flanks of gap g are reads numReads+2g (left) and numReads+2g+1 (right)
+	type gap struct {
+		scaffold, position int
+	}
+	gaps := make([]gap, 0)
+	for s := range scaffolds {
+		scaffold := &scaffolds[s]
+		for i := range scaffold.Gaps {
+			if scaffold.Closed[i] {
+				continue
+			}
+			left, right := scaffold.orientedContig(i, contigs), scaffold.orientedContig(i+1, contigs)
+			if len(left) < minMatchLength || len(right) < minMatchLength {
+				continue
+			}
+			gapReads = append(gapReads,
+				seqio.Read{ID: scaffold.ID + "_left_flank", Sequence: left[len(left)-min(flankLength, len(left)):], Multiplicity: 1},
+				seqio.Read{ID: scaffold.ID + "_right_flank", Sequence: right[:min(flankLength, len(right))], Multiplicity: 1})
+			gaps = append(gaps, gap{s, i})
+		}
+	}
+	if len(gaps) == 0 || numReads == 0 {
+		return 0, nil
+	}
+
+	strandReads := gapReads
+	if doubleStranded {
+		strandReads = DoubleStrandedReads(gapReads)
+	}
+	fmt.Println("Building a prefix and suffix index for reads and gap flanks.")
+	prefixIndex, err := index.BuildPrefixIndex(strandReads, indexLength)
+	if err != nil {
+		return 0, err
+	}
+	suffixIndex, err := index.BuildSuffixIndex(strandReads, indexLength)
+	if err != nil {
+		return 0, err
+	}
+
+	// newTracker returns a tracker in which the flanks of every other gap are
+	// already used, so that an extension doesn't wander into another gap
+	newTracker := func(g int) *ReadTracker {
+		used := NewReadTracker(len(gapReads), doubleStranded)
+		for other := range gaps {
+			if other != g {
+				used.Use(numReads + 2*other)
+				used.Use(numReads + 2*other + 1)
+			}
+		}
+		return used
+	}
+
+	closed := 0
+	for g, gap := range gaps {
+		leftIndex, rightIndex := numReads+2*g, numReads+2*g+1
+		leftFlank, rightFlank := gapReads[leftIndex].Sequence, gapReads[rightIndex].Sequence
+
+		// both extensions may use the same reads
+		used := newTracker(g)
+		used.Use(leftIndex)
+		fromLeft, _ := ExtendContigRightInexact(leftIndex, prefixIndex, suffixIndex, strandReads, minMatchLength, indexLength, verify, StopAtTie, used, nil)
+		used = newTracker(g)
+		used.Use(rightIndex)
+		fromRight, _ := ExtendContigLeftInexact(rightIndex, prefixIndex, suffixIndex, strandReads, minMatchLength, indexLength, verify, StopAtTie, used, nil)
+
+		bridge, ok := bridgeGap(fromLeft.Sequence, fromRight.Sequence, leftFlank, rightFlank, minMatchLength, indexLength, verify)
+		if !ok {
+			continue
+		}
+		scaffold := &scaffolds[gap.scaffold]
+		length := len(bridge) - len(leftFlank) - len(rightFlank)
+		scaffold.Closed[gap.position] = true
+		scaffold.Gaps[gap.position] = length
+		if length > 0 {
+			scaffold.Fills[gap.position] = bridge[len(leftFlank) : len(leftFlank)+length]
+		}
+		closed++
+	}
+	fmt.Println("Closed", closed, "of", len(gaps), "gaps.")
+
+	for s := range scaffolds {
+		scaffolds[s].spell(contigs)
+	}
+	return closed, nil
+}
+
+//bridgeGap decides what lies between two flanks, given the extension from the
+//left flank to the right (fromLeft, which starts with leftFlank) and the one
+//from the right flank to the left (fromRight, which ends with rightFlank). It
+//returns the left flank, whatever is between the flanks, and the right flank,
+//or false if neither extension reaches the other flank or the extensions
+//disagree.
+func bridgeGap(fromLeft, fromRight, leftFlank, rightFlank string, minMatchLength, indexLength int, verify OverlapVerifier) (string, bool) {
+	bridge, ok := joinFlank(fromLeft, rightFlank, minMatchLength, indexLength, verify)
+	if !ok {
+		// look for the left flank in the other extension, reading backwards
+		var reversed string
+		reversed, ok = joinFlank(kmer.Reverse(fromRight), kmer.Reverse(leftFlank), minMatchLength, indexLength, verify)
+		bridge = kmer.Reverse(reversed)
+	}
+	// the flanks may overlap, but neither can lie inside the other
+	if !ok || len(bridge) <= len(leftFlank) || len(bridge) <= len(rightFlank) {
+		return "", false
+	}
+	if !consistent(bridge, fromLeft, verify) || !consistent(kmer.Reverse(bridge), kmer.Reverse(fromRight), verify) {
+		return "", false
+	}
+	return bridge, true
+}
+
+//joinFlank looks for the start of flank in extension, trying a seed of
+//indexLength symbols every indexLength symbols along the first half of the
+//flank. If flank starts at some position p of extension, overlapping it by at
+//least minMatchLength symbols according to verify, it returns extension up to p
+//followed by flank.
+func joinFlank(extension, flank string, minMatchLength, indexLength int, verify OverlapVerifier) (string, bool) {
+	for s := 0; s+indexLength <= len(flank) && s <= len(flank)/2; s += indexLength {
+		seed := flank[s : s+indexLength]
+		for from := 0; ; {
+			q := strings.Index(extension[from:], seed)
+			if q < 0 {
+				break
+			}
+			q += from
+			from = q + 1
+			p := q - s
+			if p < 1 {
+				continue
+			}
+			n := min(len(extension)-p, len(flank))
+			if n < minMatchLength {
+				continue
+			}
+			if _, _, ok := verify(extension[p:p+n], flank[:n]); ok {
+				return extension[:p] + flank, true
+			}
+		}
+	}
+	return "", false
+}
+
+//consistent reports whether two sequences that start at the same place agree
+//for as long as both go on, according to verify.
+func consistent(a, b string, verify OverlapVerifier) bool {
+	n := min(len(a), len(b))
+	_, _, ok := verify(a[:n], b[:n])
+	return ok
+}
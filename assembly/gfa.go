@@ -0,0 +1,175 @@
+package assembly
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+//WriteGFAToFile writes an assembly graph to a GFA file. version must be 1 or 2.
+func WriteGFAToFile(assembly *Assembly, outFilename string, version int) error {
+	outFile, err := os.Create(outFilename)
+	if err != nil {
+		return err
+	}
+	err = WriteGFA(outFile, assembly, version)
+	closeErr := outFile.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+//WriteGFA writes an assembly as a GFA graph (version 1 or 2) so it can be
+//loaded into a graph viewer such as Bandage. Every read that takes part in an
+//overlap or a contig path becomes a segment, every recorded overlap becomes a
+//link (GFA 1) or edge (GFA 2) with an M-only CIGAR, and every contig becomes a
+//path (GFA 1) or ordered group (GFA 2) through the reads that extend it (reads
+//lying inside it are left out). Reads that take part reverse complemented are
+//marked with "-" instead of "+".
+func WriteGFA(w io.Writer, assembly *Assembly, version int) error {
+	if version != 1 && version != 2 {
+		return errors.New("GFA version must be 1 or 2")
+	}
+	reads := assembly.Reads
+	names := SegmentNames(reads)
+	links := make([]Link, 0)
+	if assembly.Graph != nil {
+		links = assembly.Graph.Links
+	}
+
+	// which reads take part in the graph?
+	inGraph := make(map[int]bool)
+	for _, link := range links {
+		inGraph[link.From] = true
+		inGraph[link.To] = true
+	}
+	for _, contig := range assembly.Contigs {
+		for j, i := range contig.Reads {
+			if !contig.IsContained(j) {
+				inGraph[i] = true
+			}
+		}
+	}
+	segments := make([]int, 0, len(inGraph))
+	for i := range inGraph {
+		segments = append(segments, i)
+	}
+	sort.Ints(segments)
+
+	out := bufio.NewWriter(w)
+	if version == 1 {
+		fmt.Fprintln(out, "H\tVN:Z:1.0")
+		for _, i := range segments {
+			fmt.Fprintf(out, "S\t%s\t%s\tLN:i:%d\tRC:i:%d\n", names[i], reads[i].Sequence, len(reads[i].Sequence), reads[i].Multiplicity)
+		}
+		for _, link := range links {
+			fmt.Fprintf(out, "L\t%s\t%s\t%s\t%s\t%dM\n", names[link.From], strand(link.FromReverse), names[link.To], strand(link.ToReverse), link.Overlap)
+		}
+		for c, contig := range assembly.Contigs {
+			steps := pathSteps(contig, names)
+			overlaps := "*"
+			if len(contig.Overlaps) > 0 {
+				cigars := make([]string, len(contig.Overlaps))
+				for j, overlap := range contig.Overlaps {
+					cigars[j] = strconv.Itoa(overlap) + "M"
+				}
+				overlaps = strings.Join(cigars, ",")
+			}
+			fmt.Fprintf(out, "P\t%s\t%s\t%s\n", pathName(contig, c), strings.Join(steps, ","), overlaps)
+		}
+	} else {
+		fmt.Fprintln(out, "H\tVN:Z:2.0")
+		for _, i := range segments {
+			fmt.Fprintf(out, "S\t%s\t%d\t%s\tRC:i:%d\n", names[i], len(reads[i].Sequence), reads[i].Sequence, reads[i].Multiplicity)
+		}
+		for e, link := range links {
+			// a dovetail overlap: the end of From against the start of To, where
+			// positions are on the segments as given, so the end of a reverse
+			// complemented read is the start of the segment and vice versa
+			fromLength := len(reads[link.From].Sequence)
+			toLength := len(reads[link.To].Sequence)
+			fromBegin, fromEnd := fromLength-link.Overlap, fromLength
+			if link.FromReverse {
+				fromBegin, fromEnd = 0, link.Overlap
+			}
+			toBegin, toEnd := 0, link.Overlap
+			if link.ToReverse {
+				toBegin, toEnd = toLength-link.Overlap, toLength
+			}
+			fmt.Fprintf(out, "E\te%d\t%s%s\t%s%s\t%s\t%s\t%s\t%s\t%dM\n", e+1,
+				names[link.From], strand(link.FromReverse), names[link.To], strand(link.ToReverse),
+				gfa2Position(fromBegin, fromLength), gfa2Position(fromEnd, fromLength),
+				gfa2Position(toBegin, toLength), gfa2Position(toEnd, toLength), link.Overlap)
+		}
+		for c, contig := range assembly.Contigs {
+			steps := pathSteps(contig, names)
+			fmt.Fprintf(out, "O\t%s\t%s\n", pathName(contig, c), strings.Join(steps, " "))
+		}
+	}
+	return out.Flush()
+}
+
+//pathSteps names the reads a contig's path goes through, the ones that extend
+//it, along with their orientation.
+func pathSteps(contig Contig, names []string) []string {
+	steps := make([]string, 0, len(contig.Reads))
+	for j, i := range contig.Reads {
+		if !contig.IsContained(j) {
+			steps = append(steps, names[i]+strand(contig.IsReverse(j)))
+		}
+	}
+	return steps
+}
+
+//strand is how GFA marks the orientation of a segment.
+func strand(reverse bool) string {
+	if reverse {
+		return "-"
+	}
+	return "+"
+}
+
+//gfa2Position writes a position on a segment of the given length, with the
+//trailing "$" GFA 2 wants on positions at the very end of a segment.
+func gfa2Position(position, length int) string {
+	if position == length {
+		return strconv.Itoa(position) + "$"
+	}
+	return strconv.Itoa(position)
+}
+
+//pathName is the name a contig's path gets in GFA output.
+func pathName(contig Contig, position int) string {
+	if contig.ID != "" {
+		return contig.ID
+	}
+	return ContigID(position)
+}
+
+//SegmentNames returns a name for each read to use in graph output. Read IDs
+//are used when every read has one and no two are the same; otherwise reads
+//are numbered.
+func SegmentNames(reads []seqio.Read) []string {
+	names := make([]string, len(reads))
+	seen := make(map[string]bool)
+	for i, read := range reads {
+		if read.ID == "" || seen[read.ID] {
+			// fall back to numbering everything
+			for j := range names {
+				names[j] = "read_" + strconv.Itoa(j+1)
+			}
+			return names
+		}
+		seen[read.ID] = true
+		names[i] = read.ID
+	}
+	return names
+}
@@ -0,0 +1,100 @@
+package assembly
+
+import "github.com/kaushikvemparala/Walker/seqio"
+
+//Link is a verified overlap between two reads: the last Overlap symbols of
+//read From match the first Overlap symbols of read To (indices into the read
+//collection the assembler was given). FromReverse and ToReverse say that the
+//overlap is between the reverse complement of the read rather than the read
+//itself, as happens when reads come from both strands.
+type Link struct {
+	From        int
+	To          int
+	Overlap     int
+	FromReverse bool
+	ToReverse   bool
+}
+
+//AssemblyGraph is the overlap graph an assembler discovers as it extends
+//contigs. It holds every overlap that passed verification, including ones the
+//assembler didn't follow because another read was picked first; those extra
+//links are exactly the repeats and ambiguous joins worth looking at.
+type AssemblyGraph struct {
+	Links []Link
+	seen  map[Link]bool
+}
+
+//NewAssemblyGraph returns an empty graph.
+func NewAssemblyGraph() *AssemblyGraph {
+	return &AssemblyGraph{
+		Links: make([]Link, 0),
+		seen:  make(map[Link]bool),
+	}
+}
+
+//AddLink records an overlap between two reads, ignoring repeats of a link
+//we already have. It does nothing on a nil graph, so callers that don't care
+//about the graph can pass nil.
+func (graph *AssemblyGraph) AddLink(from, to, overlap int) {
+	graph.AddOrientedLink(from, false, to, false, overlap)
+}
+
+//AddOrientedLink records an overlap between two reads, either of which may
+//take part reverse complemented. An overlap seen from the other strand (the
+//reverse complement of To followed by the reverse complement of From) is the
+//same overlap, so it is only recorded once. Like AddLink, it does nothing on
+//a nil graph.
+func (graph *AssemblyGraph) AddOrientedLink(from int, fromReverse bool, to int, toReverse bool, overlap int) {
+	if graph == nil {
+		return
+	}
+	// links are told apart by their reads and orientations, not their overlap
+	key := Link{From: from, To: to, FromReverse: fromReverse, ToReverse: toReverse}
+	mirror := Link{From: to, To: from, FromReverse: !toReverse, ToReverse: !fromReverse}
+	if graph.seen[key] || graph.seen[mirror] {
+		return
+	}
+	graph.seen[key] = true
+	key.Overlap = overlap
+	graph.Links = append(graph.Links, key)
+}
+
+//Assembly is everything an assembler produces: the contigs it kept, the read
+//collection their read indices refer to, and the overlap graph it walked. The
+//greedy assemblers place every read, but only keep contigs of some minimum
+//length; ShortContigs holds the shorter contigs, and Unplaced lists, in
+//increasing order, the reads that joined no other read.
+type Assembly struct {
+	Reads        []seqio.Read
+	Contigs      []Contig
+	Graph        *AssemblyGraph
+	ShortContigs []Contig
+	Unplaced     []int
+}
+
+//NewAssembly starts an empty assembly of the given reads.
+func NewAssembly(reads []seqio.Read) *Assembly {
+	return &Assembly{
+		Reads:        reads,
+		Contigs:      make([]Contig, 0),
+		Graph:        NewAssemblyGraph(),
+		ShortContigs: make([]Contig, 0),
+		Unplaced:     make([]int, 0),
+	}
+}
+
+//addContig files a contig a greedy assembler has finished: it is kept if it has
+//at least minContigLength symbols, and otherwise goes to ShortContigs, or, if it
+//is a single read, to Unplaced. It reports whether the contig was kept.
+func (assembly *Assembly) addContig(contig Contig, minContigLength int) bool {
+	if len(contig.Sequence) >= minContigLength {
+		assembly.Contigs = append(assembly.Contigs, contig)
+		return true
+	}
+	if len(contig.Reads) == 1 {
+		assembly.Unplaced = append(assembly.Unplaced, contig.Reads[0])
+	} else {
+		assembly.ShortContigs = append(assembly.ShortContigs, contig)
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package assembly
+
+import (
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/index"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// the prefix and suffix indices only find a read if the first (or last)
+// indexLength symbols of it occur exactly in the current read, which a single
+// error spoils. With a minimizer index (see index.MinimizerIndex), reads are
+// found by k-mers from all along them, so an overlap is found as long as a few
+// of its minimizers survive the errors.
+
+//MinSharedMinimizers is the fewest minimizers two reads must share on a
+//diagonal for GenomeAssembler4WithMinimizers to check whether they overlap.
+const MinSharedMinimizers = 3
+
+//minimizerCandidateFinder returns a candidateFinder that looks reads up in a
+//minimizer index: every read sharing at least MinSharedMinimizers minimizers
+//with the current read on a diagonal at which they would overlap by at least
+//minMatchLength symbols is checked with verify, as in findCandidates. Diagonals
+//may wander up to band symbols along an overlap.
+func minimizerCandidateFinder(minimizers *index.MinimizerIndex, reads []seqio.Read, minMatchLength, band int, verify OverlapVerifier) candidateFinder {
+	return func(_ int, currentRead string, used *ReadTracker, withPlaced, left bool) ([]OverlapCandidate, []int) {
+		candidates := make([]OverlapCandidate, 0)
+		contained := make([]int, 0)
+		n := len(currentRead)
+		for _, pair := range minimizers.FindCandidates(currentRead, reads, MinSharedMinimizers, band) {
+			i := pair.Target
+			if used.IsUsed(i) && !withPlaced {
+				continue
+			}
+			// j is where the read starts in the current read or, going left, how far
+			// before the end of the current read it ends
+			j := pair.Diagonal
+			if left {
+				j = n - (pair.Diagonal + len(reads[i].Sequence))
+			}
+			if j < 0 || j > n-minMatchLength {
+				continue
+			}
+			candidate, inside, ok := checkCandidate(currentRead, i, j, reads, verify, left)
+			if !ok {
+				continue
+			}
+			if !inside {
+				candidate.placed = used.IsUsed(i)
+				candidates = append(candidates, candidate)
+			} else if !used.IsUsed(i) {
+				contained = append(contained, i)
+			}
+		}
+		return candidates, contained
+	}
+}
+
+//GenomeAssembler4WithMinimizers is GenomeAssembler4WithVerifier with the
+//prefix and suffix indices replaced by an index of the (w,k) minimizers of the
+//reads (see index.BuildMinimizerIndex), so that overlaps are found even when
+//the ends of the reads have errors.
+func GenomeAssembler4WithMinimizers(reads []seqio.Read, minMatchLength, w, k, minContigLength int, verify OverlapVerifier, policy TiePolicy, doubleStranded bool) (*Assembly, error) {
+	err := CheckAssemblyParameters(reads, minMatchLength, k)
+	if err != nil {
+		return nil, err
+	}
+	if verify == nil {
+		return nil, &walker.ParameterError{Name: "verify", Value: nil, Reason: "must not be nil"}
+	}
+
+	strandReads := reads
+	if doubleStranded {
+		strandReads = DoubleStrandedReads(reads)
+	}
+
+	fmt.Println("Building a minimizer index for reads.")
+	minimizers, err := index.BuildMinimizerIndex(strandReads, w, k)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Minimizer index built!")
+	// insertions and deletions move the diagonal by a symbol or so each
+	find := minimizerCandidateFinder(minimizers, strandReads, minMatchLength, minMatchLength/10, verify)
+	// extensions shorter than a minimizer are too short to disagree
+	return assembleWithFinder(reads, strandReads, find, k, verify, policy, minContigLength, doubleStranded), nil
+}
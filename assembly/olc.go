@@ -0,0 +1,255 @@
+package assembly
+
+import (
+	"fmt"
+
+	"github.com/kaushikvemparala/Walker/index"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// part 6: overlap-layout-consensus
+// GenomeAssembler3 and GenomeAssembler4 commit to the first overlap they find,
+// so at a repeat they happily walk into the wrong copy. The fix is to look
+// before we leap: first find every overlap between every pair of reads (the
+// overlap step), then study the whole graph to see where the paths are
+// unambiguous (the layout step). Two tricks make the graph small enough to read:
+// a read contained in another read tells us nothing new, so we drop it, and an
+// overlap A->C is redundant when A->B->C says the same thing (transitive
+// reduction). What is left is the string graph, whose non-branching paths are
+// our contigs.
+
+//StringGraph is the overlap graph of a collection of reads after contained
+//reads are removed. Nodes are reads (both strands of every read if the graph is
+//double-stranded, numbered as in DoubleStrandedReads) and Out[i] and In[i] list
+//the overlaps of read i with the reads that can follow and precede it.
+type StringGraph struct {
+	Reads          []seqio.Read // the reads the nodes are built from
+	DoubleStranded bool
+	Contained      []bool // Contained[i] is true if read i lies inside another read
+	Out            [][]Link
+	In             [][]Link
+
+	strandReads []seqio.Read // the nodes' sequences
+}
+
+//BuildStringGraph finds every exact overlap of at least minMatchLength symbols
+//between two reads, using a prefix index of length indexLength to find the
+//candidates, and every read contained in another read. Contained reads and their
+//overlaps are left out of the graph. If doubleStranded is true, reads may
+//overlap on either strand.
+func BuildStringGraph(reads []seqio.Read, minMatchLength, indexLength int, doubleStranded bool) (*StringGraph, error) {
+	err := CheckAssemblyParameters(reads, minMatchLength, indexLength)
+	if err != nil {
+		return nil, err
+	}
+	strandReads := reads
+	if doubleStranded {
+		strandReads = DoubleStrandedReads(reads)
+	}
+	n := len(reads)
+
+	fmt.Println("Building a prefix index for reads.")
+	prefixIndex, err := index.BuildPrefixIndex(strandReads, indexLength)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &StringGraph{
+		Reads:          reads,
+		DoubleStranded: doubleStranded,
+		Contained:      make([]bool, n),
+		Out:            make([][]Link, len(strandReads)),
+		In:             make([][]Link, len(strandReads)),
+		strandReads:    strandReads,
+	}
+
+	fmt.Println("Finding all overlaps.")
+	overlaps := make([]Link, 0)
+	for a := range strandReads {
+		readA := strandReads[a].Sequence
+		originA, _ := strandOf(a, n)
+		seen := make(map[int]bool) // keep only the longest overlap with each read
+
+		// every read starting at position j of read A either lies inside A
+		// or hangs off its end
+		for _, match := range prefixIndex.Matches(readA) {
+			j := match.Position
+			for _, b := range match.Reads {
+				originB, _ := strandOf(b, n)
+				if originB == originA || seen[b] {
+					continue
+				}
+				readB := strandReads[b].Sequence
+				if len(readB) <= len(readA)-j {
+					if readA[j:j+len(readB)] == readB && (len(readB) < len(readA) || originB > originA) {
+						// B is contained in A (identical reads, e.g., a read and the reverse
+						// complement of another, only drop the later one)
+						graph.Contained[originB] = true
+					}
+				} else if j > 0 && len(readA)-j >= minMatchLength && readA[j:] == readB[:len(readA)-j] {
+					seen[b] = true
+					overlaps = append(overlaps, Link{From: a, To: b, Overlap: len(readA) - j})
+				}
+			}
+		}
+		if (a+1)%10000 == 0 {
+			fmt.Println("Update: we have found the overlaps of", a+1, "reads.")
+		}
+	}
+
+	for _, overlap := range overlaps {
+		from, _ := strandOf(overlap.From, n)
+		to, _ := strandOf(overlap.To, n)
+		if !graph.Contained[from] && !graph.Contained[to] {
+			graph.Out[overlap.From] = append(graph.Out[overlap.From], overlap)
+			graph.In[overlap.To] = append(graph.In[overlap.To], overlap)
+		}
+	}
+	return graph, nil
+}
+
+//NumOverlaps returns the number of overlaps (edges) in the graph.
+func (graph *StringGraph) NumOverlaps() int {
+	count := 0
+	for _, out := range graph.Out {
+		count += len(out)
+	}
+	return count
+}
+
+//ReduceTransitiveEdges removes every overlap A->C that is implied by two other
+//overlaps A->B and B->C: B starts inside A and C starts inside B exactly where
+//A->C says C starts inside A. It returns the number of overlaps removed.
+func (graph *StringGraph) ReduceTransitiveEdges() int {
+	reduced := make(map[[2]int]bool)
+	for a, out := range graph.Out {
+		// where does each read that follows A start inside A?
+		offset := make(map[int]int)
+		for _, link := range out {
+			offset[link.To] = len(graph.strandReads[a].Sequence) - link.Overlap
+		}
+		for _, ab := range out {
+			b := ab.To
+			for _, bc := range graph.Out[b] {
+				c := bc.To
+				offsetAC, exists := offset[c]
+				offsetBC := len(graph.strandReads[b].Sequence) - bc.Overlap
+				if exists && offset[b]+offsetBC == offsetAC {
+					reduced[[2]int{a, c}] = true
+				}
+			}
+		}
+	}
+
+	// rebuild the overlap lists without the transitive ones
+	for i := range graph.In {
+		graph.In[i] = graph.In[i][:0]
+	}
+	for a, out := range graph.Out {
+		kept := out[:0]
+		for _, link := range out {
+			if !reduced[[2]int{a, link.To}] {
+				kept = append(kept, link)
+				graph.In[link.To] = append(graph.In[link.To], link)
+			}
+		}
+		graph.Out[a] = kept
+	}
+	return len(reduced)
+}
+
+//Layout walks the unambiguous paths of the graph: a path starts at a read that
+//doesn't have exactly one way in, and follows single overlaps for as long as the
+//next read has no other way in either. Every read that isn't contained ends up
+//in exactly one contig (a read with no overlaps is a contig by itself), and in a
+//double-stranded graph, only one strand of each path is laid out. Contigs refer
+//to the reads the graph was built from.
+func (graph *StringGraph) Layout() []Contig {
+	n := len(graph.Reads)
+	used := make([]bool, n)
+	contigs := make([]Contig, 0)
+
+	continues := func(v int) bool {
+		// v just carries on a path coming from its only predecessor
+		return len(graph.In[v]) == 1 && len(graph.Out[graph.In[v][0].From]) == 1
+	}
+	walk := func(v int) {
+		origin, _ := strandOf(v, n)
+		used[origin] = true
+		contig := NewContig(v, graph.strandReads[v].Sequence)
+		for len(graph.Out[v]) == 1 {
+			link := graph.Out[v][0]
+			next, _ := strandOf(link.To, n)
+			if len(graph.In[link.To]) != 1 || used[next] {
+				break
+			}
+			used[next] = true
+			contig.AppendRead(link.To, graph.strandReads[link.To].Sequence, link.Overlap)
+			v = link.To
+		}
+		if graph.DoubleStranded {
+			contig = foldContig(contig, n)
+		}
+		contigs = append(contigs, contig)
+	}
+
+	for v := range graph.strandReads {
+		origin, _ := strandOf(v, n)
+		if !graph.Contained[origin] && !used[origin] && !continues(v) {
+			walk(v)
+		}
+	}
+	// anything left is on a cycle of reads, which we cut open anywhere
+	for v := range graph.strandReads {
+		origin, _ := strandOf(v, n)
+		if !graph.Contained[origin] && !used[origin] {
+			walk(v)
+		}
+	}
+	return contigs
+}
+
+//Links returns the overlaps of the graph in terms of the reads it was built
+//from, as an AssemblyGraph.
+func (graph *StringGraph) Links() *AssemblyGraph {
+	links := NewAssemblyGraph()
+	for _, out := range graph.Out {
+		for _, link := range out {
+			if graph.DoubleStranded {
+				from, fromReverse := strandOf(link.From, len(graph.Reads))
+				to, toReverse := strandOf(link.To, len(graph.Reads))
+				links.AddOrientedLink(from, fromReverse, to, toReverse, link.Overlap)
+			} else {
+				links.AddLink(link.From, link.To, link.Overlap)
+			}
+		}
+	}
+	return links
+}
+
+//GenomeAssemblerOLC assembles reads by overlap-layout-consensus: it builds the
+//string graph of the reads (see BuildStringGraph), removes transitive overlaps
+//and lays out its unambiguous paths as contigs. Unlike GenomeAssembler3, it
+//stops a contig at a repeat rather than guessing which way to go. Since overlaps
+//are exact, the consensus of a contig is simply the reads glued together.
+func GenomeAssemblerOLC(reads []seqio.Read, minMatchLength, indexLength int, doubleStranded bool) (*Assembly, error) {
+	graph, err := BuildStringGraph(reads, minMatchLength, indexLength, doubleStranded)
+	if err != nil {
+		return nil, err
+	}
+	contained := 0
+	for _, c := range graph.Contained {
+		if c {
+			contained++
+		}
+	}
+	fmt.Println("Found", graph.NumOverlaps(), "overlaps;", contained, "reads are contained in other reads.")
+	removed := graph.ReduceTransitiveEdges()
+	fmt.Println("Removed", removed, "transitive overlaps, leaving", graph.NumOverlaps(), "overlaps.")
+
+	assembly := NewAssembly(reads)
+	assembly.Graph = graph.Links()
+	assembly.Contigs = graph.Layout()
+	fmt.Println("Laid out", len(assembly.Contigs), "contigs.")
+	return assembly, nil
+}
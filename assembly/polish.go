@@ -0,0 +1,242 @@
+package assembly
+
+import (
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/align"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// the greedy assemblers glue reads together as they are, so a contig carries
+// every error of the reads it was glued from. But every position of the contig
+// is covered by many reads, and they rarely make the same error: if we line all
+// of them up against the contig and take a vote in every column, the majority is
+// almost always right. Fixing the contig moves the reads' alignments a little,
+// so we do it a few times (rounds), until the vote stops changing anything.
+
+//polishK is the length of the k-mers we use to find where a read sits in the
+//contigs. It is short enough that most k-mers of a read with a few percent
+//errors are error-free, and long enough to be rare in a genome.
+const polishK = 15
+
+//PolishContigs polishes contigs by majority vote: every read (and, if
+//doubleStranded is true, its reverse complement) is placed on the contig where
+//it shares the most k-mers on one diagonal and aligned there (within band
+//symbols of the diagonal, see align.Overlap), and each contig position takes the
+//base (or gap) most reads agree on, with an insertion added wherever most reads
+//have one. It runs up to rounds rounds, stopping early once a round changes
+//nothing, and returns how many corrections each round made. Contigs are changed
+//in place.
+func PolishContigs(contigs []Contig, reads []seqio.Read, rounds, band int, doubleStranded bool) ([]int, error) {
+	if rounds < 1 {
+		return nil, &walker.ParameterError{Name: "rounds", Value: rounds, Reason: "must be positive"}
+	}
+	if band < 0 {
+		return nil, &walker.ParameterError{Name: "band", Value: band, Reason: "must not be negative"}
+	}
+
+	corrections := make([]int, 0, rounds)
+	for round := 1; round <= rounds; round++ {
+		columns := make([][]columnVotes, len(contigs))
+		for c := range contigs {
+			columns[c] = make([]columnVotes, len(contigs[c].Sequence))
+		}
+		index := indexContigKmers(contigs)
+		for _, read := range reads {
+			sequence := read.Sequence
+			c, offset, votes := placeRead(sequence, index)
+			if doubleStranded {
+				reverse := kmer.ReverseComplement(sequence)
+				rc, roffset, rvotes := placeRead(reverse, index)
+				if rvotes > votes {
+					sequence, c, offset, votes = reverse, rc, roffset, rvotes
+				}
+			}
+			// a couple of shared k-mers could be chance
+			if votes < 2 {
+				continue
+			}
+			voteRead(sequence, contigs[c].Sequence, offset, band, columns[c])
+		}
+
+		total := 0
+		for c := range contigs {
+			total += applyVotes(&contigs[c], columns[c])
+		}
+		corrections = append(corrections, total)
+		fmt.Println("Polishing round", round, "made", total, "corrections.")
+		if total == 0 {
+			break
+		}
+	}
+	return corrections, nil
+}
+
+//contigPosition is where a k-mer starts in a contig.
+type contigPosition struct {
+	contig   int
+	position int
+}
+
+//indexContigKmers lists where every k-mer of length polishK occurs in the
+//contigs.
+func indexContigKmers(contigs []Contig) map[string][]contigPosition {
+	index := make(map[string][]contigPosition)
+	for c, contig := range contigs {
+		for p := 0; p+polishK <= len(contig.Sequence); p++ {
+			pattern := contig.Sequence[p : p+polishK]
+			index[pattern] = append(index[pattern], contigPosition{c, p})
+		}
+	}
+	return index
+}
+
+//placeRead finds the contig and diagonal (where the read would start in the
+//contig, which may be off either end) with the most k-mers in common with the
+//read, looking up every polishK-th k-mer of the read since that is plenty to
+//tell the diagonals apart. It returns the contig, the diagonal, and the number
+//of shared k-mers.
+func placeRead(read string, index map[string][]contigPosition) (int, int, int) {
+	diagonals := make(map[contigPosition]int)
+	for p := 0; p+polishK <= len(read); p += polishK {
+		for _, hit := range index[read[p:p+polishK]] {
+			diagonals[contigPosition{hit.contig, hit.position - p}]++
+		}
+	}
+	best, votes := contigPosition{}, 0
+	for diagonal, count := range diagonals {
+		if count > votes || (count == votes && (diagonal.contig < best.contig || (diagonal.contig == best.contig && diagonal.position < best.position))) {
+			best, votes = diagonal, count
+		}
+	}
+	return best.contig, best.position, votes
+}
+
+//columnVotes counts what the reads aligned to one contig position say it should
+//be: votes[0..3] count A, C, G, T, votes[4] counts gaps (the reads skip it), and
+//insertions counts what the reads insert just before it.
+type columnVotes struct {
+	votes      [5]int
+	insertions map[string]int
+}
+
+//baseIndex returns where a symbol is counted in columnVotes.votes, or -1 if it
+//isn't a base.
+func baseIndex(symbol byte) int {
+	switch symbol {
+	case 'A':
+		return 0
+	case 'C':
+		return 1
+	case 'G':
+		return 2
+	case 'T':
+		return 3
+	}
+	return -1
+}
+
+//voteRead aligns a read starting at offset in a contig sequence and adds what
+//it says about each position it covers to columns. The parts of the read that
+//hang off either end of the contig are cut off first.
+func voteRead(read, sequence string, offset, band int, columns []columnVotes) {
+	if offset < 0 {
+		if -offset >= len(read) {
+			return
+		}
+		read, offset = read[-offset:], 0
+	}
+	if offset >= len(sequence) {
+		return
+	}
+	if offset+len(read) > len(sequence) {
+		read = read[:len(sequence)-offset]
+	}
+	end := offset + len(read) + band
+	if end > len(sequence) {
+		end = len(sequence)
+	}
+	alignment, ok := align.Overlap(read, sequence[offset:end], band)
+	if !ok {
+		return
+	}
+
+	// walk along the read (x) and the contig (y) together
+	x, y := 0, offset
+	inserted := make([]byte, 0)
+	for _, operation := range alignment.Operations() {
+		switch operation {
+		case 'M', 'D':
+			if len(inserted) > 0 {
+				if columns[y].insertions == nil {
+					columns[y].insertions = make(map[string]int)
+				}
+				columns[y].insertions[string(inserted)]++
+				inserted = inserted[:0]
+			}
+			if operation == 'M' {
+				if b := baseIndex(read[x]); b >= 0 {
+					columns[y].votes[b]++
+				}
+				x++
+			} else {
+				columns[y].votes[4]++
+			}
+			y++
+		case 'I':
+			inserted = append(inserted, read[x])
+			x++
+		}
+	}
+}
+
+//applyVotes rebuilds a contig's sequence from the votes of the reads on each of
+//its positions and returns the number of corrections: bases changed, deleted or
+//inserted.
+func applyVotes(contig *Contig, columns []columnVotes) int {
+	sequence := contig.Sequence
+	polished := make([]byte, 0, len(sequence))
+	corrections := 0
+	for y := 0; y < len(sequence); y++ {
+		column := columns[y]
+		coverage := 0
+		for _, count := range column.votes {
+			coverage += count
+		}
+
+		// an insertion most of the reads here agree on goes in first
+		bestInsertion, insertionCount := "", 0
+		for insertion, count := range column.insertions {
+			if count > insertionCount || (count == insertionCount && insertion < bestInsertion) {
+				bestInsertion, insertionCount = insertion, count
+			}
+		}
+		if 2*insertionCount > coverage {
+			polished = append(polished, bestInsertion...)
+			corrections += len(bestInsertion)
+		}
+
+		// then whichever of the bases and the gap has the most votes, as long as
+		// it beats what the contig has now
+		current := baseIndex(sequence[y])
+		winner := current
+		for b, count := range column.votes {
+			if (winner < 0 && count > 0) || (winner >= 0 && count > column.votes[winner]) {
+				winner = b
+			}
+		}
+		switch {
+		case winner == current || winner < 0:
+			polished = append(polished, sequence[y])
+		case winner == 4:
+			corrections++ // most reads skip this position
+		default:
+			polished = append(polished, "ACGT"[winner])
+			corrections++
+		}
+	}
+	contig.Sequence = string(polished)
+	return corrections
+}
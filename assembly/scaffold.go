@@ -0,0 +1,315 @@
+package assembly
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// the assemblers stop at repeats and at places no read covers, so a genome
+// comes out in many contigs, in no particular order or orientation. Paired
+// reads can put them back in order: the two mates of a pair come from the two
+// ends of a fragment of (roughly) known length, so when they land on two
+// different contigs, they say which ends of the contigs face each other and
+// about how far apart they are. Enough pairs agreeing on a link is good
+// evidence; we join contigs along the best-supported links into scaffolds,
+// with a run of Ns standing in for each gap.
+
+//PairOrientation says which way the mates of a library face.
+type PairOrientation int
+
+const (
+	//PairedEnd mates face each other (forward-reverse), as in a paired-end
+	//library.
+	PairedEnd PairOrientation = iota
+	//MatePair mates face away from each other (reverse-forward), as in a
+	//long-insert mate-pair library.
+	MatePair
+)
+
+//MinGapLength is the fewest Ns we put between two contigs of a scaffold, even
+//if the mates say the contigs overlap or touch: the join is only a guess, and
+//the Ns say so.
+const MinGapLength = 10
+
+//Scaffold is an ordered and oriented chain of contigs. Contigs holds indices
+//into the contig collection given to the scaffolder, from left to right, and
+//Reverse[i] is true if Contigs[i] appears reverse complemented. Gaps[i] is the
+//estimated number of symbols between Contigs[i] and Contigs[i+1] (negative if
+//they seem to overlap) and Links[i] is the number of pairs behind that join.
+//Once a gap is closed (see FillGaps), Closed[i] is true, Fills[i] holds the
+//symbols between the two contigs and Gaps[i] is exactly len(Fills[i]), or minus
+//the number of symbols the contigs overlap by, which Fills[i] leaves out.
+//Sequence spells the scaffold out with at least MinGapLength Ns per open gap.
+type Scaffold struct {
+	ID       string
+	Sequence string
+	Contigs  []int
+	Reverse  []bool
+	Gaps     []int
+	Links    []int
+	Closed   []bool
+	Fills    []string
+}
+
+//orientedContig returns the i-th contig of the scaffold as it appears there.
+func (scaffold *Scaffold) orientedContig(i int, contigs []Contig) string {
+	sequence := contigs[scaffold.Contigs[i]].Sequence
+	if scaffold.Reverse[i] {
+		return kmer.ReverseComplement(sequence)
+	}
+	return sequence
+}
+
+//spell sets the sequence of the scaffold from its contigs: a run of Ns for
+//every open gap, and the fill for every closed one.
+func (scaffold *Scaffold) spell(contigs []Contig) {
+	var sequence strings.Builder
+	for i := range scaffold.Contigs {
+		contig := scaffold.orientedContig(i, contigs)
+		if i > 0 {
+			gap := scaffold.Gaps[i-1]
+			switch {
+			case !scaffold.Closed[i-1]:
+				sequence.WriteString(strings.Repeat("N", gapLength(gap)))
+			case gap < 0:
+				// the contigs overlap, so the overlap is already written
+				contig = contig[-gap:]
+			default:
+				sequence.WriteString(scaffold.Fills[i-1])
+			}
+		}
+		sequence.WriteString(contig)
+	}
+	scaffold.Sequence = sequence.String()
+}
+
+//contigEnd is one end of a contig: its right end if right is true, its left
+//end otherwise.
+type contigEnd struct {
+	contig int
+	right  bool
+}
+
+//scaffoldLink is a bundle of pairs joining two contig ends, with the gaps each
+//pair estimates between them.
+type scaffoldLink struct {
+	a, b contigEnd
+	gaps []int
+}
+
+//ScaffoldContigs orders and orients contigs using paired reads from a library
+//with fragments of about insertSize symbols (give or take insertSD). Both
+//mates of every pair are placed on the contigs as in PolishContigs; a pair
+//whose mates land on two different contigs links the contig ends the mates
+//face, and estimates the gap between them. Links backed by at least minLinks
+//pairs are taken greedily, most pairs first, as long as both contig ends are
+//still free and the link doesn't close a cycle. It returns every contig in
+//some scaffold, contigs that got no links alone in scaffolds of their own,
+//named scaffold_1, scaffold_2, ... in order of their first contig.
+func ScaffoldContigs(contigs []Contig, pairs []seqio.ReadPair, insertSize, insertSD, minLinks int, orientation PairOrientation) ([]Scaffold, error) {
+	if insertSize < 1 {
+		return nil, &walker.ParameterError{Name: "insertSize", Value: insertSize, Reason: "must be positive"}
+	}
+	if insertSD < 0 {
+		return nil, &walker.ParameterError{Name: "insertSD", Value: insertSD, Reason: "must not be negative"}
+	}
+	if minLinks < 1 {
+		return nil, &walker.ParameterError{Name: "minLinks", Value: minLinks, Reason: "must be positive"}
+	}
+
+	links := findLinks(contigs, pairs, insertSize, insertSD, orientation)
+	fmt.Println("Found", len(links), "links between contig ends.")
+
+	// the best supported links first
+	sort.Slice(links, func(i, j int) bool {
+		if len(links[i].gaps) != len(links[j].gaps) {
+			return len(links[i].gaps) > len(links[j].gaps)
+		}
+		if links[i].a != links[j].a {
+			return endLess(links[i].a, links[j].a)
+		}
+		return endLess(links[i].b, links[j].b)
+	})
+
+	// partner[end] is the link we took at a contig end; component tells which
+	// contigs are already chained together, so that we never close a cycle
+	partner := make(map[contigEnd]*scaffoldLink)
+	component := make([]int, len(contigs))
+	for c := range component {
+		component[c] = c
+	}
+	accepted := 0
+	for i := range links {
+		link := &links[i]
+		if len(link.gaps) < minLinks {
+			break
+		}
+		if partner[link.a] != nil || partner[link.b] != nil {
+			continue
+		}
+		ca, cb := findComponent(component, link.a.contig), findComponent(component, link.b.contig)
+		if ca == cb {
+			continue
+		}
+		component[ca] = cb
+		partner[link.a], partner[link.b] = link, link
+		accepted++
+	}
+	fmt.Println("Joined contigs along", accepted, "links supported by at least", minLinks, "pairs.")
+
+	return chainScaffolds(contigs, partner), nil
+}
+
+//endLess orders contig ends, so that sorting links is deterministic.
+func endLess(a, b contigEnd) bool {
+	if a.contig != b.contig {
+		return a.contig < b.contig
+	}
+	return !a.right && b.right
+}
+
+//findComponent follows component from contig c to the contig that stands for
+//its chain.
+func findComponent(component []int, c int) int {
+	for component[c] != c {
+		component[c] = component[component[c]]
+		c = component[c]
+	}
+	return c
+}
+
+//findLinks places the mates of every pair on the contigs and bundles the pairs
+//whose mates land on different contigs by the contig ends they link.
+func findLinks(contigs []Contig, pairs []seqio.ReadPair, insertSize, insertSD int, orientation PairOrientation) []scaffoldLink {
+	index := indexContigKmers(contigs)
+	bundles := make(map[[2]contigEnd]int) // index into links
+	links := make([]scaffoldLink, 0)
+
+	for _, pair := range pairs {
+		first, second := pair.First.Sequence, pair.Second.Sequence
+		if orientation == MatePair {
+			// mates facing away from each other are mates facing each other, read
+			// from the other strand
+			first, second = kmer.ReverseComplement(first), kmer.ReverseComplement(second)
+		}
+		endA, distanceA, ok := placeMate(first, contigs, index)
+		if !ok {
+			continue
+		}
+		endB, distanceB, ok := placeMate(second, contigs, index)
+		if !ok || endA.contig == endB.contig {
+			continue
+		}
+		// the fragment runs from one mate, off the end of its contig, across the
+		// gap and onto the other contig up to the other mate
+		gap := insertSize - distanceA - distanceB
+		if gap < -3*insertSD-2*MinGapLength {
+			// the contigs would overlap more than the fragment allows
+			continue
+		}
+		if endLess(endB, endA) {
+			endA, endB = endB, endA
+		}
+		key := [2]contigEnd{endA, endB}
+		i, seen := bundles[key]
+		if !seen {
+			i = len(links)
+			bundles[key] = i
+			links = append(links, scaffoldLink{a: endA, b: endB})
+		}
+		links[i].gaps = append(links[i].gaps, gap)
+	}
+	return links
+}
+
+//placeMate finds where a mate lies in the contigs, on either strand. A mate
+//points towards the end of the contig it reads towards: it returns that end,
+//and the distance from where the mate starts to that end.
+func placeMate(mate string, contigs []Contig, index map[string][]contigPosition) (contigEnd, int, bool) {
+	c, offset, votes := placeRead(mate, index)
+	reverse := false
+	rc, roffset, rvotes := placeRead(kmer.ReverseComplement(mate), index)
+	if rvotes > votes {
+		c, offset, votes, reverse = rc, roffset, rvotes, true
+	}
+	// a couple of shared k-mers could be chance
+	if votes < 2 {
+		return contigEnd{}, 0, false
+	}
+	if reverse {
+		// read backwards from its start, offset + len(mate), to the left end
+		return contigEnd{c, false}, offset + len(mate), true
+	}
+	return contigEnd{c, true}, len(contigs[c].Sequence) - offset, true
+}
+
+//gapLength returns how many Ns stand for an estimated gap in a scaffold.
+func gapLength(gap int) int {
+	if gap < MinGapLength {
+		return MinGapLength
+	}
+	return gap
+}
+
+//median returns the middle value of a non-empty list of numbers.
+func median(values []int) int {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted[len(sorted)/2]
+}
+
+//chainScaffolds follows the links taken at the contig ends from contig to
+//contig and spells out the scaffolds, with every gap open.
+func chainScaffolds(contigs []Contig, partner map[contigEnd]*scaffoldLink) []Scaffold {
+	scaffolds := make([]Scaffold, 0)
+	placed := make([]bool, len(contigs))
+	for start := range contigs {
+		// a chain starts at a contig with a free end; we read it from that end
+		if placed[start] {
+			continue
+		}
+		reverse := false
+		if partner[contigEnd{start, false}] != nil {
+			if partner[contigEnd{start, true}] != nil {
+				continue // in the middle of a chain
+			}
+			reverse = true
+		}
+
+		scaffold := Scaffold{}
+		c := start
+		for {
+			placed[c] = true
+			scaffold.Contigs = append(scaffold.Contigs, c)
+			scaffold.Reverse = append(scaffold.Reverse, reverse)
+
+			// we leave a forward contig by its right end and a reversed one by its left
+			exit := contigEnd{c, !reverse}
+			link := partner[exit]
+			if link == nil {
+				break
+			}
+			next := link.b
+			if next == exit {
+				next = link.a
+			}
+			gap := median(link.gaps)
+			scaffold.Gaps = append(scaffold.Gaps, gap)
+			scaffold.Links = append(scaffold.Links, len(link.gaps))
+			scaffold.Closed = append(scaffold.Closed, false)
+			scaffold.Fills = append(scaffold.Fills, "")
+			// we enter the next contig by the end the link reaches
+			c, reverse = next.contig, next.right
+		}
+		scaffold.ID = "scaffold_" + strconv.Itoa(len(scaffolds)+1)
+		scaffold.spell(contigs)
+		scaffolds = append(scaffolds, scaffold)
+	}
+	return scaffolds
+}
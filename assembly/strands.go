@@ -0,0 +1,70 @@
+package assembly
+
+import (
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// DNA is double-stranded, and a sequencer reads whichever strand it happens to
+// grab, so about half of our reads are reverse complements of the genome. The
+// trick for assembling them is to hand the assemblers every read twice: once as
+// it is and once reverse complemented. Every overlap can then be found on one
+// strand or the other with the same index lookups as before. When we use a read,
+// we also mark its other strand as used (see ReadTracker), so that it doesn't go
+// on to seed a copy of the same contig running the other way.
+
+//DoubleStrandedReads takes a collection of n reads and returns 2n reads: the
+//reads themselves, followed by their reverse complements in the same order, so
+//that read n+i is read i as seen from the other strand. Qualities are reversed
+//along with the sequences.
+func DoubleStrandedReads(reads []seqio.Read) []seqio.Read {
+	n := len(reads)
+	doubled := make([]seqio.Read, 2*n)
+	copy(doubled, reads)
+	for i, read := range reads {
+		rc := read
+		rc.Sequence = kmer.ReverseComplement(read.Sequence)
+		if read.Quality != nil {
+			rc.Quality = make([]byte, len(read.Quality))
+			for j := range read.Quality {
+				rc.Quality[j] = read.Quality[len(read.Quality)-1-j]
+			}
+		}
+		doubled[n+i] = rc
+	}
+	return doubled
+}
+
+//strandOf takes the index of a read in the output of DoubleStrandedReads (for
+//n reads) and returns the index of the original read and whether it is the
+//reverse complement.
+func strandOf(i, n int) (int, bool) {
+	if i >= n {
+		return i - n, true
+	}
+	return i, false
+}
+
+//foldContig takes a contig built from the output of DoubleStrandedReads (for n
+//reads) and rewrites it in terms of the original reads, marking the ones that
+//were used reverse complemented.
+func foldContig(contig Contig, n int) Contig {
+	contig.Reverse = make([]bool, len(contig.Reads))
+	reads := make([]int, len(contig.Reads))
+	for j, i := range contig.Reads {
+		reads[j], contig.Reverse[j] = strandOf(i, n)
+	}
+	contig.Reads = reads
+	return contig
+}
+
+//foldGraph copies the links of a graph built from the output of
+//DoubleStrandedReads (for n reads) into graph, rewriting them in terms of the
+//original reads. The same overlap found on both strands is only copied once.
+func foldGraph(doubled *AssemblyGraph, n int, graph *AssemblyGraph) {
+	for _, link := range doubled.Links {
+		from, fromReverse := strandOf(link.From, n)
+		to, toReverse := strandOf(link.To, n)
+		graph.AddOrientedLink(from, fromReverse, to, toReverse, link.Overlap)
+	}
+}
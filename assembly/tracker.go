@@ -0,0 +1,77 @@
+package assembly
+
+// the greedy assemblers used to mark a read as used by deleting its prefix and
+// suffix from the indices. But the indices are keyed by index string, so that
+// threw out every other read sharing the prefix too, and a read whose suffix was
+// deleted could still be reached through its prefix. Instead, we keep the
+// indices as they are and remember which reads we've used, one read at a time.
+
+//ReadTracker remembers which reads have been placed in a contig. Reads are
+//numbered as the assembler sees them: if the tracker is double-stranded, there
+//are 2n of them, numbered as in DoubleStrandedReads, and placing a read places
+//its other strand too.
+type ReadTracker struct {
+	used           []bool // used[i] is true if read i (or its other strand) was placed
+	doubleStranded bool
+	numUsed        int
+	next           int // every read before next is used
+}
+
+//NewReadTracker returns a tracker for numReads reads, none of them used yet.
+//If doubleStranded is true, the assembler sees 2*numReads reads (see
+//DoubleStrandedReads).
+func NewReadTracker(numReads int, doubleStranded bool) *ReadTracker {
+	return &ReadTracker{
+		used:           make([]bool, numReads),
+		doubleStranded: doubleStranded,
+	}
+}
+
+//origin returns the index of the read that read i is a strand of.
+func (tracker *ReadTracker) origin(i int) int {
+	if tracker.doubleStranded {
+		i, _ = strandOf(i, len(tracker.used))
+	}
+	return i
+}
+
+//Use marks read i (and its other strand) as placed.
+func (tracker *ReadTracker) Use(i int) {
+	i = tracker.origin(i)
+	if !tracker.used[i] {
+		tracker.used[i] = true
+		tracker.numUsed++
+	}
+}
+
+//IsUsed reports whether read i (or its other strand) has been placed.
+func (tracker *ReadTracker) IsUsed(i int) bool {
+	return tracker.used[tracker.origin(i)]
+}
+
+//NumUnused returns the number of reads that haven't been placed, counting a
+//read and its other strand once.
+func (tracker *ReadTracker) NumUnused() int {
+	return len(tracker.used) - tracker.numUsed
+}
+
+//Next returns the first read that hasn't been placed (on its forward strand),
+//or false if every read has been.
+func (tracker *ReadTracker) Next() (int, bool) {
+	for tracker.next < len(tracker.used) && tracker.used[tracker.next] {
+		tracker.next++
+	}
+	return tracker.next, tracker.next < len(tracker.used)
+}
+
+//Unused returns the reads that haven't been placed, in increasing order (one
+//index per read, on its forward strand).
+func (tracker *ReadTracker) Unused() []int {
+	unused := make([]int, 0, tracker.NumUnused())
+	for i, used := range tracker.used {
+		if !used {
+			unused = append(unused, i)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,79 @@
+package assembly
+
+import (
+	"math"
+
+	"github.com/kaushikvemparala/Walker/align"
+	"github.com/kaushikvemparala/Walker/kmer"
+)
+
+//OverlapVerifier decides whether suffix, the end of one read, overlaps the
+//start of read, another read. If it does, it returns how many symbols at the
+//start of read the overlap covers and the fraction of the overlap that matches
+//(its identity, which may be an estimate). The overlap length is len(suffix) for
+//a verifier that only allows substitutions, but insertions and deletions make it
+//a little shorter or longer.
+type OverlapVerifier func(suffix, read string) (int, float64, bool)
+
+//ExactVerifier is the overlap test of GenomeAssembler3: read must start with
+//suffix, symbol for symbol.
+func ExactVerifier(suffix, read string) (int, float64, bool) {
+	if len(read) < len(suffix) || read[:len(suffix)] != suffix {
+		return 0, 0.0, false
+	}
+	return len(suffix), 1.0, true
+}
+
+//SharedKmerVerifier returns the overlap test GenomeAssembler4 has always used:
+//the overlapping stretches must share about as many k-mers as two copies of the
+//same string with errorRate errors would (see SharedKmerOverlap).
+func SharedKmerVerifier(errorRate float64, k int) OverlapVerifier {
+	return SharedKmerModelVerifier(kmer.SubstitutionModel(errorRate), k, DefaultSharedKmerConfidence)
+}
+
+//SharedKmerModelVerifier is SharedKmerVerifier for reads whose errors are
+//described by model, accepting the overlaps that two copies of the same string
+//would pass with probability confidence (see SharedKmerOverlapWithModel).
+func SharedKmerModelVerifier(model kmer.ErrorModel, k int, confidence float64) OverlapVerifier {
+	return func(suffix, read string) (int, float64, bool) {
+		if len(read) < len(suffix) {
+			return 0, 0.0, false
+		}
+		shared := kmer.CountSharedKmers(suffix, read[:len(suffix)], k)
+		if float64(shared) < kmer.SharedKmerThreshold(len(suffix), model, k, confidence) {
+			return 0, 0.0, false
+		}
+		return len(suffix), sharedKmerIdentity(shared, len(suffix), k), true
+	}
+}
+
+//sharedKmerIdentity estimates the identity of two strings of length
+//stringLength that share shared k-mers: a k-mer survives with probability
+//identity^k, so identity is about the k-th root of the fraction of k-mers shared.
+func sharedKmerIdentity(shared, stringLength, k int) float64 {
+	numKmers := stringLength - k + 1
+	if numKmers <= 0 {
+		return 0.0
+	}
+	fraction := math.Min(1.0, float64(shared)/float64(numKmers))
+	return math.Pow(fraction, 1.0/float64(k))
+}
+
+//AlignmentVerifier returns an overlap test that aligns suffix against the start
+//of read (see align.Overlap) and accepts the overlap if at least minIdentity of
+//the alignment columns are matches. The alignment may wander up to band symbols
+//off the diagonal; band <= 0 sizes the band to the most indels an overlap with
+//minIdentity could have.
+func AlignmentVerifier(minIdentity float64, band int) OverlapVerifier {
+	return func(suffix, read string) (int, float64, bool) {
+		b := band
+		if b <= 0 {
+			b = int((1.0-minIdentity)*float64(len(suffix))) + 1
+		}
+		alignment, ok := align.Overlap(suffix, read, b)
+		if !ok || alignment.Identity() < minIdentity {
+			return 0, 0.0, false
+		}
+		return alignment.BLength, alignment.Identity(), true
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/assembly"
+	"github.com/kaushikvemparala/Walker/index"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+	"github.com/kaushikvemparala/Walker/stats"
+)
+
+func runAssemble(args []string) error {
+	fs := newFlagSet("assemble", "[flags] --in reads.fasta",
+		"Assemble reads a FASTA or FASTQ file, throws out short reads and assembles\n"+
+			"the rest into contigs, which are written as FASTA. The overlap graph behind\n"+
+			"the contigs can also be written as GFA, e.g., for looking at repeats in Bandage.\n\n"+
+			"Assemblers (--algo):\n\n"+
+			"  exact     join reads only when their overlap matches exactly (GenomeAssembler3)\n"+
+			"  inexact   tolerate sequencing errors by comparing the shared k-mers of overlaps\n"+
+			"            (GenomeAssembler4), or by aligning them with --verifier=align\n"+
+			"  debruijn  build the de Bruijn graph of the reads' k-mers and report its unitigs\n"+
+			"            (GenomeAssemblerDeBruijn); suits many short reads at high coverage\n"+
+			"  olc       find all exact overlaps, drop contained reads and transitive overlaps,\n"+
+			"            and lay out the unambiguous paths (GenomeAssemblerOLC), stopping at\n"+
+			"            repeats rather than guessing")
+	in := fs.String("in", "", "input FASTA or FASTQ file (required)")
+	algo := fs.String("algo", "inexact", "assembler to use: exact, inexact, debruijn or olc")
+	minReadLength := fs.Int("min-read-length", 1000, "throw out reads shorter than this")
+	minMatchLength := fs.Int("min-match-length", 800, "shortest overlap between two reads we believe")
+	minContigLength := fs.Int("min-contig-length", 100000, "set shorter contigs aside as unplaced (exact and inexact only)")
+	indexLength := fs.Int("index-length", 15, "length of the read prefixes and suffixes we index")
+	k := fs.Int("k", 0, "k-mer length for comparing overlaps (inexact, default 7) or of graph edges (debruijn, default 31)")
+	minKmerCount := fs.Int("min-kmer-count", 2, "throw out k-mers seen fewer times than this (debruijn only)")
+	errorRate := fs.Float64("error-rate", 0.11, "expected sequencing error rate (inexact only)")
+	indelRate := fs.Float64("indel-rate", 0, "expected rate of insertions and deletions, half each, on top of --error-rate substitutions (--verifier=kmer only)")
+	confidence := fs.Float64("confidence", assembly.DefaultSharedKmerConfidence, "fraction of true overlaps to accept with --verifier=kmer")
+	verifier := fs.String("verifier", "kmer", "how to check overlaps (inexact only): kmer (shared k-mers) or align (banded alignment)")
+	minIdentity := fs.Float64("min-identity", 0, "smallest alignment identity to accept with --verifier=align (0 for 1 - 2.5 * error-rate)")
+	band := fs.Int("band", 0, "alignment band width with --verifier=align (0 sizes it from --min-identity)")
+	indexType := fs.String("index", "prefix", "how to find overlap candidates: prefix (exact read ends), fm (an FM-index of the reads, exact only) or minimizer (minimizers along the reads, inexact only)")
+	minimizerW := fs.Int("minimizer-w", 10, "number of consecutive k-mers each minimizer stands for with --index=minimizer")
+	minimizerK := fs.Int("minimizer-k", 12, "minimizer length with --index=minimizer")
+	ties := fs.String("ties", "best", "what to do when the reads that could extend a contig disagree (exact and inexact only): stop, best or branch")
+	polishRounds := fs.Int("polish-rounds", 0, "rounds of majority-vote polishing of the contigs against their reads (0 to skip)")
+	polishBand := fs.Int("polish-band", 50, "alignment band width for polishing")
+	singleStranded := fs.Bool("single-stranded", false, "assume every read comes from the same strand instead of either one")
+	out := fs.String("out", "assembly_contigs.fasta", "output FASTA file for the contigs")
+	unplaced := fs.String("unplaced", "assembly_unplaced.fasta", "output FASTA file for short contigs and unplaced reads (empty to skip)")
+	gfa := fs.String("gfa", "assembly_graph.gfa", "output GFA file for the assembly graph (empty to skip)")
+	gfaVersion := fs.Int("gfa-version", 1, "GFA version to write: 1 or 2")
+	lineWidth := fs.Int("line-width", seqio.DefaultFASTALineWidth, "FASTA line width (0 for one line per contig)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	err := firstError(
+		required("in", *in),
+		required("out", *out),
+		atLeast("min-read-length", *minReadLength, 0),
+		atLeast("index-length", *indexLength, 1),
+		atLeast("min-match-length", *minMatchLength, *indexLength),
+		atLeast("min-contig-length", *minContigLength, 0),
+		atLeast("minimizer-w", *minimizerW, 1),
+		atLeast("minimizer-k", *minimizerK, 1),
+		atLeast("polish-rounds", *polishRounds, 0),
+		atLeast("polish-band", *polishBand, 0),
+		atLeast("line-width", *lineWidth, 0),
+	)
+	if err != nil {
+		return err
+	}
+	policy := assembly.BestCandidate
+	switch *ties {
+	case "stop":
+		policy = assembly.StopAtTie
+	case "best":
+	case "branch":
+		policy = assembly.BranchAtTie
+	default:
+		return &walker.ParameterError{Name: "--ties", Value: *ties, Reason: "must be stop, best or branch"}
+	}
+	switch *algo {
+	case "exact":
+		if *indexType != "prefix" && *indexType != "fm" {
+			err = &walker.ParameterError{Name: "--index", Value: *indexType, Reason: "must be prefix or fm with --algo exact"}
+		}
+	case "olc":
+	case "inexact":
+		if *k == 0 {
+			*k = 7
+		}
+		if *minIdentity == 0 {
+			// each of the two reads has errors, so their overlap has about twice as many
+			*minIdentity = 1 - 2.5**errorRate
+		}
+		err = firstError(
+			atLeast("k", *k, 1),
+			atLeast("min-match-length", *minMatchLength, *k),
+			probability("error-rate", *errorRate),
+			probability("indel-rate", *indelRate),
+			probability("confidence", *confidence),
+			probability("min-identity", *minIdentity),
+			atLeast("band", *band, 0),
+		)
+		if err == nil && *verifier != "kmer" && *verifier != "align" {
+			err = &walker.ParameterError{Name: "--verifier", Value: *verifier, Reason: "must be kmer or align"}
+		}
+		if err == nil && *indexType != "prefix" && *indexType != "minimizer" {
+			err = &walker.ParameterError{Name: "--index", Value: *indexType, Reason: "must be prefix or minimizer with --algo inexact"}
+		}
+		if err == nil && *indexType == "minimizer" {
+			err = atLeast("min-match-length", *minMatchLength, *minimizerK+1)
+		}
+	case "debruijn":
+		if *k == 0 {
+			*k = 31
+		}
+		err = firstError(
+			atLeast("k", *k, 2),
+			atLeast("min-kmer-count", *minKmerCount, 1),
+		)
+	default:
+		return &walker.ParameterError{Name: "--algo", Value: *algo, Reason: "must be exact, inexact, debruijn or olc"}
+	}
+	if err != nil {
+		return err
+	}
+	if *gfaVersion != 1 && *gfaVersion != 2 {
+		return &walker.ParameterError{Name: "--gfa-version", Value: *gfaVersion, Reason: "must be 1 or 2"}
+	}
+
+	// we read the file once, without holding the reads we throw out in memory:
+	// statistics of every read are tallied as it goes by, and the assemblers
+	// that look overlaps up by read ends have the reads we keep indexed as they
+	// come in, rather than building the indices afterwards.
+	stream, err := seqio.OpenReadStream(*in)
+	if err != nil {
+		return err
+	}
+	counted := stats.NewStatisticsStream(stream)
+	kept := seqio.FilterReadStream(counted, *minReadLength)
+	indexed := *indexType == "prefix" && (*algo == "exact" || *algo == "inexact")
+	var reads []seqio.Read
+	var prefixIndex, suffixIndex *index.EndIndex
+	if indexed {
+		fmt.Println("Indexing the prefixes and suffixes of reads of length >=", *minReadLength)
+		reads, prefixIndex, suffixIndex, err = index.IndexReadStream(kept, *indexLength)
+	} else {
+		reads, err = seqio.CollectReads(kept)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("We have", counted.Statistics.Count, "total reads.")
+	counted.Statistics.Print()
+
+	fmt.Println("Threw out short reads of length <", *minReadLength)
+	if len(reads) == 0 {
+		return fmt.Errorf("no reads of length >= %d left to assemble: %w", *minReadLength, walker.ErrNoReads)
+	}
+	fmt.Println("Updated read stats.")
+	stats.PrintStatistics(seqio.ReadSequences(reads))
+
+	fmt.Println("Calling assembler.")
+	var result *assembly.Assembly
+	switch *algo {
+	case "exact":
+		if *indexType == "fm" {
+			result, err = assembly.GenomeAssembler3WithFMIndex(reads, *minMatchLength, *minContigLength, policy, !*singleStranded)
+		} else {
+			result, err = assembly.GenomeAssembler3Indexed(reads, prefixIndex, suffixIndex, *minMatchLength, *minContigLength, policy, !*singleStranded)
+		}
+	case "inexact":
+		var verify assembly.OverlapVerifier
+		if *verifier == "align" {
+			verify = assembly.AlignmentVerifier(*minIdentity, *band)
+		} else {
+			model := kmer.ErrorModel{Substitution: *errorRate, Insertion: *indelRate / 2, Deletion: *indelRate / 2}
+			verify = assembly.SharedKmerModelVerifier(model, *k, *confidence)
+		}
+		if *indexType == "minimizer" {
+			result, err = assembly.GenomeAssembler4WithMinimizers(reads, *minMatchLength, *minimizerW, *minimizerK, *minContigLength, verify, policy, !*singleStranded)
+		} else {
+			result, err = assembly.GenomeAssembler4Indexed(reads, prefixIndex, suffixIndex, *minMatchLength, *minContigLength, verify, policy, !*singleStranded)
+		}
+	case "olc":
+		result, err = assembly.GenomeAssemblerOLC(reads, *minMatchLength, *indexLength, !*singleStranded)
+	case "debruijn":
+		result, err = assembly.GenomeAssemblerDeBruijn(reads, *k, *minKmerCount, !*singleStranded)
+	}
+	if err != nil {
+		return err
+	}
+	contigs := result.Contigs
+	fmt.Println(len(contigs), "total contigs.")
+	if len(result.ShortContigs) > 0 || len(result.Unplaced) > 0 {
+		fmt.Println("Set aside", len(result.ShortContigs), "contigs shorter than", *minContigLength, "and", len(result.Unplaced), "reads that joined no other read.")
+	}
+	if *polishRounds > 0 && len(contigs) > 0 {
+		fmt.Println("Polishing contigs.")
+		_, err = assembly.PolishContigs(contigs, reads, *polishRounds, *polishBand, !*singleStranded)
+		if err != nil {
+			return err
+		}
+	}
+	if len(contigs) > 0 {
+		stats.PrintStatistics(assembly.ContigSequences(contigs))
+	}
+
+	assembly.NameContigs(contigs)
+	// contig read indices refer to the assembly's reads, which for the de
+	// Bruijn assembler are its unitigs rather than the input reads
+	err = assembly.WriteContigsToFile(contigs, result.Reads, *out, *lineWidth)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Wrote contigs to", *out)
+	if *unplaced != "" {
+		err = assembly.WriteUnplacedToFile(result, *unplaced, *lineWidth)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Wrote short contigs and unplaced reads to", *unplaced)
+	}
+	if *gfa != "" {
+		err = assembly.WriteGFAToFile(result, *gfa, *gfaVersion)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Wrote the assembly graph to", *gfa)
+	}
+	return nil
+}
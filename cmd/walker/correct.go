@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/correct"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+func runCorrect(args []string) error {
+	fs := newFlagSet("correct", "[flags] --in reads.fasta",
+		"Correct counts the k-mers of every read in a FASTA or FASTQ file and fixes\n"+
+			"substitution errors: wherever a read goes from solid (frequently seen) k-mers\n"+
+			"to weak (rarely seen) ones, it tries the other bases at the symbol that comes\n"+
+			"in and keeps the one that makes the k-mers solid again. The corrected reads\n"+
+			"are written as FASTA, ready for assemble.")
+	in := fs.String("in", "", "input FASTA or FASTQ file (required)")
+	out := fs.String("out", "corrected_reads.fasta", "output FASTA file for the corrected reads")
+	k := fs.Int("k", 15, "k-mer length")
+	minCount := fs.Int("min-count", 0, "fewest times a solid k-mer is seen (0 picks it from the k-mer spectrum)")
+	singleStranded := fs.Bool("single-stranded", false, "count k-mers on the strand of each read only, instead of both strands")
+	lineWidth := fs.Int("line-width", seqio.DefaultFASTALineWidth, "FASTA line width (0 for one line per read)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	err := firstError(
+		required("in", *in),
+		required("out", *out),
+		atLeast("k", *k, 2),
+		atLeast("min-count", *minCount, 0),
+		atLeast("line-width", *lineWidth, 0),
+	)
+	if err != nil {
+		return err
+	}
+
+	stream, err := seqio.OpenReadStream(*in)
+	if err != nil {
+		return err
+	}
+	reads, err := seqio.CollectReads(stream)
+	if err != nil {
+		return err
+	}
+	if len(reads) == 0 {
+		return fmt.Errorf("%s: %w", *in, walker.ErrNoReads)
+	}
+	fmt.Println("Correcting", len(reads), "reads.")
+
+	corrected, report, err := correct.CorrectReads(reads, *k, *minCount, !*singleStranded)
+	if err != nil {
+		return err
+	}
+	report.Print()
+
+	err = writeFASTAFile(*out, corrected, *lineWidth)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Wrote corrected reads to", *out)
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kaushikvemparala/Walker/fmindex"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+	"github.com/kaushikvemparala/Walker/stats"
+)
+
+func runEvaluate(args []string) error {
+	fs := newFlagSet("evaluate", "[flags] --contigs contigs.fasta",
+		"Evaluate prints the number of contigs, their lengths and N50. Given the\n"+
+			"reference genome (e.g., from walker simulate), it also counts the contigs\n"+
+			"that occur exactly in the reference, on either strand, and how much of the\n"+
+			"reference those contigs cover.")
+	contigsIn := fs.String("contigs", "", "FASTA file of contigs (required)")
+	referenceIn := fs.String("reference", "", "FASTA file of the reference genome")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := required("contigs", *contigsIn); err != nil {
+		return err
+	}
+
+	contigs, err := readSequences(*contigsIn)
+	if err != nil {
+		return err
+	}
+	if len(contigs) == 0 {
+		return fmt.Errorf("%s has no contigs", *contigsIn)
+	}
+	fmt.Println("Number of contigs:", len(contigs))
+	stats.PrintStatistics(contigs)
+	fmt.Println("N50:", stats.N50(contigs))
+
+	if *referenceIn == "" {
+		return nil
+	}
+	reference, err := readSequences(*referenceIn)
+	if err != nil {
+		return err
+	}
+
+	// covered[i][j] is true if position j of reference sequence i is inside
+	// a contig that matches there exactly. Every contig is looked up in every
+	// reference sequence, so we index them once
+	covered := make([][]bool, len(reference))
+	indices := make([]*fmindex.Index, len(reference))
+	for i := range reference {
+		covered[i] = make([]bool, len(reference[i]))
+		indices[i] = fmindex.New(reference[i])
+	}
+	found := 0
+	for _, contig := range contigs {
+		if markOccurrences(contig, indices, covered) || markOccurrences(kmer.ReverseComplement(contig), indices, covered) {
+			found++
+		}
+	}
+
+	referenceLength := stats.TotalStringLength(reference)
+	coveredLength := 0
+	for i := range covered {
+		for _, c := range covered[i] {
+			if c {
+				coveredLength++
+			}
+		}
+	}
+	fmt.Println("Reference length:", referenceLength)
+	fmt.Println("Contigs found in reference:", found, "of", len(contigs))
+	if referenceLength > 0 {
+		fmt.Printf("Reference covered: %.2f%%\n", 100*float64(coveredLength)/float64(referenceLength))
+	}
+	return nil
+}
+
+//markOccurrences finds every occurrence of pattern in the reference sequences
+//(through their FM-indices), marks the positions it covers, and reports
+//whether there was any.
+func markOccurrences(pattern string, indices []*fmindex.Index, covered [][]bool) bool {
+	if pattern == "" {
+		return false
+	}
+	found := false
+	for i, index := range indices {
+		for _, start := range kmer.StartingIndicesIndex(pattern, index) {
+			found = true
+			for p := start; p < start+len(pattern); p++ {
+				covered[i][p] = true
+			}
+		}
+	}
+	return found
+}
+
+//readSequences returns the sequences in a FASTA or FASTQ file, in order and
+//without collapsing duplicates.
+func readSequences(filename string) ([]string, error) {
+	stream, err := seqio.OpenReadStream(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	sequences := make([]string, 0)
+	for stream.Next() {
+		sequences = append(sequences, stream.Read().Sequence)
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return sequences, nil
+}
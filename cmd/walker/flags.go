@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	walker "github.com/kaushikvemparala/Walker"
+)
+
+var (
+	// errUsage means the command line was wrong in a way the flag package
+	// cannot see, such as a missing required flag.
+	errUsage = errors.New("bad usage")
+	// errBadFlags means the flag package rejected the command line and has
+	// already printed why, along with the help text.
+	errBadFlags = errors.New("bad flags")
+)
+
+//newFlagSet makes the flag set for a command. arguments describes what comes
+//after the command name and description is printed above the flags in the help.
+func newFlagSet(name, arguments, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: walker %s %s\n\n%s\n\nFlags:\n", name, arguments, description)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+//parseFlags parses args into fs, turning the flag package's errors into ours.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return errBadFlags
+}
+
+//required complains about a flag that was left empty.
+func required(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("%w: --%s is required", errUsage, name)
+	}
+	return nil
+}
+
+//atLeast complains about an integer flag smaller than min.
+func atLeast(name string, value, min int) error {
+	if value < min {
+		return &walker.ParameterError{Name: "--" + name, Value: value, Reason: fmt.Sprintf("must be at least %d", min)}
+	}
+	return nil
+}
+
+//probability complains about a rate flag outside [0, 1).
+func probability(name string, value float64) error {
+	if value < 0 || value >= 1 {
+		return &walker.ParameterError{Name: "--" + name, Value: value, Reason: "must be in [0, 1)"}
+	}
+	return nil
+}
+
+//firstError returns the first non-nil error, so a command can list all of
+//its checks in one place.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//writable makes sure we can write every output file that was asked for
+//(empty names are skipped) before a command does any long work, so that a
+//typo in a path doesn't throw that work away. It creates the files that don't
+//exist yet, but leaves the contents of the others alone.
+func writable(filenames ...string) error {
+	for _, filename := range filenames {
+		if filename == "" {
+			continue
+		}
+		file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE, 0666)
+		if err != nil {
+			return err
+		}
+		file.Close()
+	}
+	return nil
+}
+
+//seedRandom starts the pseudo random number generation at seed, or somewhere
+//seemingly random if seed is 0.
+func seedRandom(seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rand.Seed(seed)
+}
@@ -0,0 +1,118 @@
+//The walker command runs the pieces of the Walker library from the command
+//line: simulating genomes and reads, summarizing read files, correcting reads,
+//assembling reads into contigs, scaffolding contigs with paired reads and
+//evaluating the contigs we get.
+//
+//Usage:
+//
+//	walker <command> [flags]
+//
+//Run "walker help <command>" for the flags of a command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	walker "github.com/kaushikvemparala/Walker"
+)
+
+//command is one subcommand of walker. run gets the arguments after the
+//command name and returns an error if the command failed.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"simulate", "generate a random genome and simulated reads from it", runSimulate},
+	{"stats", "print length statistics of FASTA/FASTQ files", runStats},
+	{"correct", "fix sequencing errors in reads using their k-mer spectrum", runCorrect},
+	{"assemble", "assemble reads into contigs", runAssemble},
+	{"scaffold", "order and orient contigs into scaffolds using paired reads", runScaffold},
+	{"evaluate", "summarize contigs and compare them to a reference", runEvaluate},
+	{"reconstruct", "find every genome with the k-mer composition of a genome", runReconstruct},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name, args := os.Args[1], os.Args[2:]
+
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		os.Exit(help(args))
+	}
+
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "walker: unknown command %q\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	err := cmd.run(args)
+	if errors.Is(err, flag.ErrHelp) {
+		// the flag package has already printed the help text
+		os.Exit(0)
+	}
+	if errors.Is(err, errBadFlags) {
+		// so has the complaint about the flags
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "walker %s: %v\n", cmd.name, err)
+		// bad flags get the conventional exit status 2, everything else 1
+		if errors.Is(err, errUsage) || errors.Is(err, walker.ErrInvalidParameter) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+//help prints help for the command named in args (or general usage) and
+//returns the exit status.
+func help(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return 0
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "walker: unknown command %q\n", args[0])
+		usage()
+		return 2
+	}
+	// every command prints its help when asked for -h
+	cmd.run([]string{"-h"})
+	return 0
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Walker assembles genomes (or tries at least).")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "\twalker <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run \"walker help <command>\" for the flags of a command.")
+}
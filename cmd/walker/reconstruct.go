@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/kaushikvemparala/Walker/assembly"
+	"github.com/kaushikvemparala/Walker/seqio"
+	"github.com/kaushikvemparala/Walker/simulate"
+)
+
+func runReconstruct(args []string) error {
+	fs := newFlagSet("reconstruct", "[flags] --genome genome.fasta",
+		"Reconstruct takes the perfect k-mer composition of each genome in a FASTA\n"+
+			"file and finds every genome with that composition by walking Eulerian paths.\n"+
+			"It reports whether the original genome is the only reconstruction, which is\n"+
+			"the best any k-mer based assembler could hope to do.")
+	genomeIn := fs.String("genome", "", "FASTA file of genomes (required)")
+	k := fs.Int("k", 20, "k-mer length")
+	limit := fs.Int("limit", 10, "stop after finding this many reconstructions (0 for no limit, 1 skips the uniqueness check)")
+	out := fs.String("out", "", "output FASTA file for the reconstructions")
+	lineWidth := fs.Int("line-width", seqio.DefaultFASTALineWidth, "FASTA line width (0 for one line per sequence)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	err := firstError(
+		required("genome", *genomeIn),
+		atLeast("k", *k, 2),
+		atLeast("limit", *limit, 0),
+		atLeast("line-width", *lineWidth, 0),
+	)
+	if err != nil {
+		return err
+	}
+
+	genomes, err := readSequences(*genomeIn)
+	if err != nil {
+		return err
+	}
+	all := make([]seqio.Read, 0)
+	for i, genome := range genomes {
+		if len(genome) < *k {
+			return fmt.Errorf("genome %d has length %d, shorter than k = %d", i+1, len(genome), *k)
+		}
+		reconstructions, err := assembly.EulerianReconstructions(simulate.KmerComposition(genome, *k), *limit)
+		// if the search gave up, we still report what it found
+		undetermined := errors.Is(err, assembly.ErrReconstructionUndetermined)
+		if err != nil && !undetermined {
+			return err
+		}
+		found := false
+		for j, reconstruction := range reconstructions {
+			if reconstruction == genome {
+				found = true
+			}
+			all = append(all, seqio.Read{
+				ID:       "genome_" + strconv.Itoa(i+1) + "_reconstruction_" + strconv.Itoa(j+1),
+				Sequence: reconstruction,
+			})
+		}
+
+		fmt.Println("Genome", i+1, "of length", len(genome))
+		if undetermined {
+			fmt.Println("The search gave up before finding every reconstruction.")
+		}
+		if undetermined || (*limit > 0 && len(reconstructions) == *limit) {
+			fmt.Println("Reconstructions: at least", len(reconstructions))
+		} else {
+			fmt.Println("Reconstructions:", len(reconstructions))
+		}
+		fmt.Println("Original genome among them:", found)
+		if undetermined && len(reconstructions) < 2 {
+			fmt.Println("Uniquely reconstructable: undetermined")
+		} else if *limit != 1 {
+			fmt.Println("Uniquely reconstructable:", len(reconstructions) == 1)
+		}
+	}
+
+	if *out != "" {
+		err = writeFASTAFile(*out, all, *lineWidth)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Wrote", len(all), "reconstructions to", *out)
+	}
+	return nil
+}
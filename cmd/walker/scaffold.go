@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/assembly"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+func runScaffold(args []string) error {
+	fs := newFlagSet("scaffold", "[flags] --contigs contigs.fasta (--reads1 r1.fastq --reads2 r2.fastq | --interleaved pairs.fastq)",
+		"Scaffold orders and orients contigs (e.g., from walker assemble) using paired\n"+
+			"reads, given as two FASTA or FASTQ files of first and second mates or as one\n"+
+			"interleaved file. Pairs whose mates land on two different contigs link the\n"+
+			"contig ends they face; links backed by at least --min-links pairs join the\n"+
+			"contigs into scaffolds, with each gap, as estimated from --insert-size, filled\n"+
+			"with Ns. Given --leftover reads (e.g., the unplaced reads from assemble), it\n"+
+			"then tries to close each gap by extending both contigs into it with those\n"+
+			"reads, as the inexact assembler extends contigs. The scaffolds are written as\n"+
+			"FASTA and their layout as AGP, along with the sequences that closed the gaps.")
+	contigsIn := fs.String("contigs", "", "FASTA file of contigs (required)")
+	reads1 := fs.String("reads1", "", "FASTA or FASTQ file of first mates")
+	reads2 := fs.String("reads2", "", "FASTA or FASTQ file of second mates")
+	interleaved := fs.String("interleaved", "", "FASTA or FASTQ file of pairs, each read followed by its mate")
+	library := fs.String("library", "pe", "library type: pe (paired-end, mates face each other) or mp (mate-pair, mates face away)")
+	insertSize := fs.Int("insert-size", 500, "average fragment length of the library")
+	insertSD := fs.Int("insert-sd", 50, "standard deviation of the fragment length")
+	minLinks := fs.Int("min-links", 3, "fewest pairs needed to join two contigs")
+	leftover := fs.String("leftover", "", "FASTA or FASTQ file of reads to close gaps with (empty to leave the gaps open)")
+	minMatchLength := fs.Int("min-match-length", 800, "shortest overlap between a read and a gap flank we believe")
+	indexLength := fs.Int("index-length", 15, "length of the read prefixes and suffixes we index for gap filling")
+	errorRate := fs.Float64("error-rate", 0.11, "expected sequencing error rate of the leftover reads")
+	k := fs.Int("k", 7, "k-mer length for comparing overlaps when filling gaps")
+	singleStranded := fs.Bool("single-stranded", false, "assume every leftover read comes from the same strand instead of either one")
+	out := fs.String("out", "scaffolds.fasta", "output FASTA file for the scaffolds")
+	agp := fs.String("agp", "scaffolds.agp", "output AGP file for the scaffold layout (empty to skip)")
+	fills := fs.String("fills", "scaffold_fills.fasta", "output FASTA file for the sequences that closed gaps, which the AGP file refers to")
+	lineWidth := fs.Int("line-width", seqio.DefaultFASTALineWidth, "FASTA line width (0 for one line per scaffold)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	err := firstError(
+		required("contigs", *contigsIn),
+		required("out", *out),
+		atLeast("insert-size", *insertSize, 1),
+		atLeast("insert-sd", *insertSD, 0),
+		atLeast("min-links", *minLinks, 1),
+		atLeast("line-width", *lineWidth, 0),
+		atLeast("index-length", *indexLength, 1),
+		atLeast("min-match-length", *minMatchLength, *indexLength+1),
+		atLeast("k", *k, 1),
+		probability("error-rate", *errorRate),
+	)
+	if err != nil {
+		return err
+	}
+	if (*interleaved == "") == (*reads1 == "" && *reads2 == "") {
+		return fmt.Errorf("%w: give either --reads1 and --reads2 or --interleaved", errUsage)
+	}
+	if *interleaved == "" {
+		err = firstError(required("reads1", *reads1), required("reads2", *reads2))
+		if err != nil {
+			return err
+		}
+	}
+	orientation := assembly.PairedEnd
+	switch *library {
+	case "pe":
+	case "mp":
+		orientation = assembly.MatePair
+	default:
+		return &walker.ParameterError{Name: "--library", Value: *library, Reason: "must be pe or mp"}
+	}
+	err = writable(*out, *agp, *fills)
+	if err != nil {
+		return err
+	}
+
+	contigReads, err := seqio.CollectReadsFromFASTA(*contigsIn)
+	if err != nil {
+		return err
+	}
+	if len(contigReads) == 0 {
+		return fmt.Errorf("%s has no contigs", *contigsIn)
+	}
+	contigs := make([]assembly.Contig, len(contigReads))
+	for i, read := range contigReads {
+		contigs[i] = assembly.Contig{ID: read.ID, Sequence: read.Sequence}
+	}
+
+	pairs, err := readPairs(*reads1, *reads2, *interleaved)
+	if err != nil {
+		return err
+	}
+	if len(pairs) == 0 {
+		return fmt.Errorf("no pairs: %w", walker.ErrNoReads)
+	}
+	// we read every input before the long work, so a bad file stops us early
+	var reads []seqio.Read
+	if *leftover != "" {
+		stream, err := seqio.OpenReadStream(*leftover)
+		if err != nil {
+			return err
+		}
+		reads, err = seqio.CollectReads(stream)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Println("Scaffolding", len(contigs), "contigs with", len(pairs), "pairs.")
+
+	scaffolds, err := assembly.ScaffoldContigs(contigs, pairs, *insertSize, *insertSD, *minLinks, orientation)
+	if err != nil {
+		return err
+	}
+	fmt.Println(len(scaffolds), "total scaffolds.")
+
+	if *leftover != "" {
+		fmt.Println("Filling gaps with", len(reads), "leftover reads.")
+		verify := assembly.SharedKmerVerifier(*errorRate, *k)
+		_, err = assembly.FillGaps(scaffolds, contigs, reads, *minMatchLength, *indexLength, verify, !*singleStranded)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = assembly.WriteScaffoldsToFile(scaffolds, *out, *lineWidth)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Wrote scaffolds to", *out)
+	if *agp != "" {
+		err = assembly.WriteAGPToFile(scaffolds, contigs, *agp)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Wrote the scaffold layout to", *agp)
+	}
+	if *fills != "" {
+		err = writeFASTAFile(*fills, assembly.ScaffoldFills(scaffolds), *lineWidth)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Wrote the sequences that closed gaps to", *fills)
+	}
+	return nil
+}
+
+//readPairs collects paired reads from two files of mates, or from one
+//interleaved file if interleaved isn't empty.
+func readPairs(reads1, reads2, interleaved string) ([]seqio.ReadPair, error) {
+	if interleaved != "" {
+		stream, err := seqio.OpenReadStream(interleaved)
+		if err != nil {
+			return nil, err
+		}
+		return seqio.CollectInterleavedPairs(stream)
+	}
+	first, err := seqio.OpenReadStream(reads1)
+	if err != nil {
+		return nil, err
+	}
+	second, err := seqio.OpenReadStream(reads2)
+	if err != nil {
+		first.Close()
+		return nil, err
+	}
+	return seqio.CollectPairs(first, second)
+}
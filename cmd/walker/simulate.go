@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/seqio"
+	"github.com/kaushikvemparala/Walker/simulate"
+)
+
+func runSimulate(args []string) error {
+	fs := newFlagSet("simulate", "[flags]",
+		"Simulate generates a random genome and samples reads from it, optionally\n"+
+			"mutating each read symbol with probability --error-rate to mimic sequencing\n"+
+			"errors. Unless --single-stranded is given, each read comes from either strand\n"+
+			"of the genome with equal chance. The genome and the reads are written as FASTA.\n"+
+			"With --paired, it samples pairs of reads facing each other from the ends of\n"+
+			"fragments about --insert-size long instead, writing the first mates to\n"+
+			"--reads and the second mates to --reads2.")
+	length := fs.Int("length", 100000, "genome length")
+	minReadLength := fs.Int("min-read-length", 500, "shortest read to sample")
+	maxReadLength := fs.Int("max-read-length", 1000, "longest read to sample")
+	coverage := fs.Int("coverage", 30, "average number of reads covering each genome position")
+	errorRate := fs.Float64("error-rate", 0.0, "probability that a read symbol is mutated")
+	paired := fs.Bool("paired", false, "sample paired reads from the ends of genome fragments")
+	mateLength := fs.Int("mate-length", 150, "length of each mate with --paired")
+	insertSize := fs.Int("insert-size", 500, "average fragment length with --paired")
+	insertSD := fs.Int("insert-sd", 50, "standard deviation of the fragment length with --paired")
+	singleStranded := fs.Bool("single-stranded", false, "sample every read from the given strand of the genome")
+	seed := fs.Int64("seed", 0, "random seed (0 picks one from the clock)")
+	genomeOut := fs.String("genome", "genome.fasta", "output FASTA file for the genome")
+	readsOut := fs.String("reads", "reads.fasta", "output FASTA file for the reads (the first mates with --paired)")
+	reads2Out := fs.String("reads2", "reads_2.fasta", "output FASTA file for the second mates with --paired")
+	lineWidth := fs.Int("line-width", seqio.DefaultFASTALineWidth, "FASTA line width (0 for one line per sequence)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	err := firstError(
+		atLeast("length", *length, 1),
+		atLeast("min-read-length", *minReadLength, 1),
+		atLeast("max-read-length", *maxReadLength, *minReadLength),
+		atLeast("length", *length, *maxReadLength),
+		atLeast("coverage", *coverage, 1),
+		probability("error-rate", *errorRate),
+		atLeast("line-width", *lineWidth, 0),
+		required("genome", *genomeOut),
+		required("reads", *readsOut),
+	)
+	if err != nil {
+		return err
+	}
+	if *paired {
+		err = firstError(
+			atLeast("mate-length", *mateLength, 1),
+			atLeast("insert-size", *insertSize, *mateLength),
+			atLeast("length", *length, *insertSize),
+			atLeast("insert-sd", *insertSD, 0),
+			required("reads2", *reads2Out),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	seedRandom(*seed)
+	genome := simulate.GenerateRandomGenome(*length)
+	err = writeFASTAFile(*genomeOut, []seqio.Read{{ID: "genome", Sequence: genome}}, *lineWidth)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Wrote a genome of length", len(genome), "to", *genomeOut)
+	if *paired {
+		return simulatePairs(genome, *mateLength, *insertSize, *insertSD, *coverage, *errorRate, *singleStranded, *readsOut, *reads2Out, *lineWidth)
+	}
+
+	sequences := simulate.SimulateReads(genome, *minReadLength, *maxReadLength, *coverage)
+	for i := range sequences {
+		if !*singleStranded && rand.Intn(2) == 1 {
+			sequences[i] = kmer.ReverseComplement(sequences[i])
+		}
+		if *errorRate > 0 {
+			sequences[i] = simulate.MutateDNAString(sequences[i], *errorRate)
+		}
+	}
+	reads := seqio.ReadsFromStrings(sequences)
+
+	err = writeFASTAFile(*readsOut, reads, *lineWidth)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Wrote", len(reads), "reads to", *readsOut)
+	return nil
+}
+
+//simulatePairs samples paired reads from genome and writes the first and second
+//mates to two FASTA files, with matching IDs (pair_1/1 and pair_1/2, ...).
+func simulatePairs(genome string, mateLength, insertSize, insertSD, coverage int, errorRate float64, singleStranded bool, firstOut, secondOut string, lineWidth int) error {
+	first, second := simulate.SimulatePairedReads(genome, mateLength, insertSize, insertSD, coverage)
+	firstReads := make([]seqio.Read, len(first))
+	secondReads := make([]seqio.Read, len(second))
+	for i := range first {
+		// a fragment from the other strand is read from its other end first
+		if !singleStranded && rand.Intn(2) == 1 {
+			first[i], second[i] = second[i], first[i]
+		}
+		if errorRate > 0 {
+			first[i] = simulate.MutateDNAString(first[i], errorRate)
+			second[i] = simulate.MutateDNAString(second[i], errorRate)
+		}
+		id := "pair_" + strconv.Itoa(i+1)
+		firstReads[i] = seqio.Read{ID: id + "/1", Sequence: first[i], Multiplicity: 1}
+		secondReads[i] = seqio.Read{ID: id + "/2", Sequence: second[i], Multiplicity: 1}
+	}
+
+	err := writeFASTAFile(firstOut, firstReads, lineWidth)
+	if err != nil {
+		return err
+	}
+	err = writeFASTAFile(secondOut, secondReads, lineWidth)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Wrote", len(first), "pairs of reads to", firstOut, "and", secondOut)
+	return nil
+}
+
+//writeFASTAFile writes reads to a FASTA file, one record per read.
+func writeFASTAFile(filename string, reads []seqio.Read, lineWidth int) error {
+	outFile, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(outFile)
+	for _, read := range reads {
+		err = seqio.WriteFASTA(out, read.ID, read.Description, read.Sequence, lineWidth)
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	closeErr := outFile.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kaushikvemparala/Walker/seqio"
+	"github.com/kaushikvemparala/Walker/stats"
+)
+
+func runStats(args []string) error {
+	fs := newFlagSet("stats", "[flags] file...",
+		"Stats prints the number of reads and their length statistics for each\n"+
+			"FASTA or FASTQ file (plain, gzip, bzip2 or zstd), streaming over the file\n"+
+			"rather than loading it.")
+	minReadLength := fs.Int("min-read-length", 0, "only count valid DNA reads at least this long (0 counts every read)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: no input files given", errUsage)
+	}
+	if err := atLeast("min-read-length", *minReadLength, 0); err != nil {
+		return err
+	}
+
+	for i, filename := range fs.Args() {
+		stream, err := seqio.OpenReadStream(filename)
+		if err != nil {
+			return err
+		}
+		if *minReadLength > 0 {
+			stream = seqio.FilterReadStream(stream, *minReadLength)
+		}
+		readStats, err := stats.StreamStatistics(stream)
+		stream.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(filename)
+		readStats.Print()
+	}
+	return nil
+}
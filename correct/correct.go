@@ -0,0 +1,166 @@
+package correct
+
+import (
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+//Report summarizes an error correction run: the k-mer length and solid k-mer
+//count used, how many distinct k-mers were solid and weak, how many reads were
+//changed, and how many bases were changed in total.
+type Report struct {
+	K              int
+	MinCount       int
+	SolidKmers     int
+	WeakKmers      int
+	Reads          int
+	CorrectedReads int
+	Corrections    int
+}
+
+//Print prints a report, one line per number.
+func (report Report) Print() {
+	fmt.Println("k-mer length:", report.K)
+	fmt.Println("Solid k-mers are seen at least", report.MinCount, "times.")
+	fmt.Println("Solid k-mers:", report.SolidKmers)
+	fmt.Println("Weak k-mers:", report.WeakKmers)
+	fmt.Println("Reads corrected:", report.CorrectedReads, "of", report.Reads)
+	fmt.Println("Bases changed:", report.Corrections)
+}
+
+//CorrectReads fixes substitution errors in reads using their k-mer spectrum
+//(see CountKmers). A k-mer is solid if it is seen at least minCount times, and
+//weak otherwise; minCount <= 0 picks the threshold from the spectrum (see
+//SolidThreshold). Wherever a solid k-mer of a read is followed by a weak one, we
+//try the other three bases at the symbol that comes in, and keep the one that
+//starts the longest run of solid k-mers, if no other base does as well (see
+//correctRead). It returns the corrected reads (the input is left alone) and a report.
+func CorrectReads(reads []seqio.Read, k, minCount int, doubleStranded bool) ([]seqio.Read, Report, error) {
+	// a 1-mer can't tell one position from another
+	if k < 2 {
+		return nil, Report{}, &walker.ParameterError{Name: "k", Value: k, Reason: "must be at least 2"}
+	}
+	spectrum, err := CountKmers(reads, k, doubleStranded)
+	if err != nil {
+		return nil, Report{}, err
+	}
+	if minCount <= 0 {
+		minCount = spectrum.SolidThreshold()
+	}
+	report := Report{K: k, MinCount: minCount, Reads: len(reads)}
+	for count, kmers := range spectrum.Histogram() {
+		if count >= minCount {
+			report.SolidKmers += kmers
+		} else {
+			report.WeakKmers += kmers
+		}
+	}
+
+	corrected := make([]seqio.Read, len(reads))
+	copy(corrected, reads)
+	for i, read := range reads {
+		if len(read.Sequence) < k || !seqio.ValidDNAString(read.Sequence) {
+			continue
+		}
+		sequence, changes := correctRead(read.Sequence, spectrum, minCount)
+		if changes > 0 {
+			corrected[i].Sequence = sequence
+			report.CorrectedReads++
+			report.Corrections += changes
+		}
+		if (i+1)%100000 == 0 {
+			fmt.Println("Update: we have corrected", i+1, "reads.")
+		}
+	}
+	return corrected, report, nil
+}
+
+//correctRead fixes the substitutions in sequence and returns the corrected
+//sequence and how many bases it changed. We start from the first solid k-mer
+//and walk right, one k-mer at a time: as long as the k-mers are solid, the read
+//agrees with the genome. When a solid k-mer is followed by a weak one, the base
+//that just came in is probably an error, so we try the other three bases there.
+//Then we do the same walking left from where we started.
+func correctRead(sequence string, spectrum *Spectrum, minCount int) (string, int) {
+	k := spectrum.K
+	symbols := []byte(sequence)
+	numKmers := len(symbols) - k + 1
+
+	anchor := 0
+	for anchor < numKmers && !isSolid(symbols, anchor, spectrum, minCount) {
+		anchor++
+	}
+	if anchor == numKmers {
+		// nothing in this read looks like the genome, so we can't tell what to fix
+		return sequence, 0
+	}
+
+	changes := 0
+	for i := anchor; i+1 < numKmers; i++ {
+		if isSolid(symbols, i, spectrum, minCount) && !isSolid(symbols, i+1, spectrum, minCount) {
+			// the error is probably at the last symbol of k-mer i+1
+			if fixBase(symbols, i+k, i+1, 1, spectrum, minCount) {
+				changes++
+			}
+		}
+	}
+	for i := anchor; i > 0; i-- {
+		if isSolid(symbols, i, spectrum, minCount) && !isSolid(symbols, i-1, spectrum, minCount) {
+			// the error is probably at the first symbol of k-mer i-1
+			if fixBase(symbols, i-1, i-1, -1, spectrum, minCount) {
+				changes++
+			}
+		}
+	}
+	return string(symbols), changes
+}
+
+//minRun is the fewest solid k-mers in a row a new base must give us (unless the
+//read ends first). A single solid k-mer can be a chance match to somewhere else
+//in the genome, and taking it would lead the rest of the walk astray.
+const minRun = 2
+
+//fixBase tries the other three bases at position p of symbols, where the k-mer
+//starting at kmerStart is weak. The best base is the one followed by the longest
+//run of solid k-mers (up to k of them) in direction step (1 for right, -1 for
+//left), starting at kmerStart. It changes p and returns true if exactly one base
+//is best and its run is long enough (see minRun).
+func fixBase(symbols []byte, p, kmerStart, step int, spectrum *Spectrum, minCount int) bool {
+	original := symbols[p]
+	bestBase, bestRun, tied := original, 0, false
+	for _, base := range []byte("ACGT") {
+		if base == original {
+			continue
+		}
+		symbols[p] = base
+		run, i := 0, kmerStart
+		for run < spectrum.K && i >= 0 && i+spectrum.K <= len(symbols) && isSolid(symbols, i, spectrum, minCount) {
+			run++
+			i += step
+		}
+		if i < 0 || i+spectrum.K > len(symbols) {
+			// the read ended before the run did, so the run is as long as it gets
+			run = spectrum.K
+		}
+		if run > bestRun {
+			bestBase, bestRun, tied = base, run, false
+		} else if run == bestRun && run > 0 {
+			tied = true
+		}
+	}
+	// we only change a base if one alternative clearly wins
+	if bestRun >= minRun && !tied {
+		symbols[p] = bestBase
+		return true
+	}
+	symbols[p] = original
+	return false
+}
+
+//isSolid reports whether the k-mer of symbols starting at i is seen at least
+//minCount times.
+func isSolid(symbols []byte, i int, spectrum *Spectrum, minCount int) bool {
+	return spectrum.Count(string(symbols[i:i+spectrum.K])) >= minCount
+}
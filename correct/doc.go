@@ -0,0 +1,7 @@
+//Package correct fixes sequencing errors in reads before assembly, using the
+//k-mer spectrum of the reads: k-mers from the genome show up about as many times
+//as the coverage, while k-mers containing an error are rare. Where a read goes
+//from solid (frequent) k-mers to weak (rare) ones, the symbol that comes in is
+//probably an error, and the base that makes the k-mers solid again is probably
+//the right one.
+package correct
@@ -0,0 +1,95 @@
+package correct
+
+import (
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/kmer"
+	"github.com/kaushikvemparala/Walker/packed"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+//Spectrum counts the k-mers of a collection of reads. Counts holds how many
+//times each k-mer was seen across the reads, packed into a number (see
+//packed.Kmer); k-mers longer than packed.MaxKmerLength are packed into several
+//words and counted in LongCounts instead (see packed.LongKmer).
+type Spectrum struct {
+	K          int
+	Counts     map[packed.Kmer]int
+	LongCounts map[packed.LongKmer]int
+}
+
+//CountKmers takes a collection of reads and counts their k-mers with
+//kmer.CountReadKmers, or kmer.CountReadLongKmers for long k-mers (each read
+//counts as many times as its multiplicity). Reads shorter than k or with
+//symbols other than A, C, G, T are skipped. If doubleStranded is true, every
+//read is counted on both strands, so a k-mer and its reverse complement always
+//have the same count.
+func CountKmers(reads []seqio.Read, k int, doubleStranded bool) (*Spectrum, error) {
+	if len(reads) == 0 {
+		return nil, fmt.Errorf("CountKmers: %w", walker.ErrNoReads)
+	}
+	spectrum := &Spectrum{K: k}
+	var err error
+	if k <= packed.MaxKmerLength {
+		spectrum.Counts, err = kmer.CountReadKmers(reads, k, doubleStranded)
+	} else {
+		spectrum.LongCounts, err = kmer.CountReadLongKmers(reads, k, doubleStranded)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return spectrum, nil
+}
+
+//Count returns how many times a k-mer was seen, or 0 if it has symbols other
+//than A, C, G, T.
+func (spectrum *Spectrum) Count(pattern string) int {
+	if spectrum.K > packed.MaxKmerLength {
+		kmer, ok := packed.EncodeLongKmer(pattern)
+		if !ok {
+			return 0
+		}
+		return spectrum.LongCounts[kmer]
+	}
+	kmer, ok := packed.EncodeKmer(pattern)
+	if !ok {
+		return 0
+	}
+	return spectrum.Counts[kmer]
+}
+
+//Histogram returns the k-mer spectrum proper: histogram[c] is the number of
+//distinct k-mers seen exactly c times.
+func (spectrum *Spectrum) Histogram() []int {
+	histogram := make([]int, 2)
+	add := func(count int) {
+		for len(histogram) <= count {
+			histogram = append(histogram, 0)
+		}
+		histogram[count]++
+	}
+	// only one of the two maps has anything in it
+	for _, count := range spectrum.Counts {
+		add(count)
+	}
+	for _, count := range spectrum.LongCounts {
+		add(count)
+	}
+	return histogram
+}
+
+//SolidThreshold picks the smallest count of a solid k-mer from the histogram.
+//Error k-mers pile up at count 1 and thin out from there, while genome k-mers
+//form a hump around the coverage, so we cut at the valley between them: the
+//first count whose number of k-mers stops falling. If there is no valley (e.g.,
+//at low coverage), it returns 2, so that only k-mers seen once are weak.
+func (spectrum *Spectrum) SolidThreshold() int {
+	histogram := spectrum.Histogram()
+	for c := 2; c+1 < len(histogram); c++ {
+		if histogram[c+1] >= histogram[c] {
+			return c
+		}
+	}
+	return 2
+}
@@ -0,0 +1,65 @@
+//Package walker holds the errors shared by Walker's packages. Every error
+//returned for bad input wraps one of the sentinels below, so callers can sort
+//errors with errors.Is (and pull out details with errors.As) without caring
+//which package produced them.
+//
+//The code itself lives in subpackages:
+//
+//	seqio     reading FASTA/FASTQ (plain or compressed) and the Read type
+//	kmer      k-mer counting and sequence utilities
+//	index     prefix/suffix and minimizer indices over reads
+//	fmindex   suffix arrays and FM-indices for substring and overlap queries
+//	packed    2-bit packed sequences and k-mers
+//	align     banded alignment for checking overlaps
+//	correct   k-mer spectrum error correction of reads
+//	assembly  the genome assemblers, contigs and assembly graph output
+//	simulate  random genomes, mutations and simulated reads
+//	stats     length statistics for reads and contigs
+//
+//The walker command in cmd/walker puts them together.
+package walker
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoReads means an assembler or index was given nothing to work with.
+	ErrNoReads = errors.New("no reads given")
+	// ErrInvalidParameter means a numeric parameter is out of range.
+	ErrInvalidParameter = errors.New("invalid parameter")
+	// ErrReadTooShort means a read is shorter than an algorithm needs.
+	ErrReadTooShort = errors.New("read too short")
+)
+
+//ParameterError reports a parameter with a value we can't work with.
+type ParameterError struct {
+	Name   string
+	Value  interface{}
+	Reason string
+}
+
+func (e *ParameterError) Error() string {
+	return fmt.Sprintf("invalid %s = %v: %s", e.Name, e.Value, e.Reason)
+}
+
+func (e *ParameterError) Unwrap() error {
+	return ErrInvalidParameter
+}
+
+//ReadLengthError reports a read that is too short, e.g., for the index length.
+type ReadLengthError struct {
+	Index    int // position of the read in its collection
+	ID       string
+	Length   int
+	Required int
+}
+
+func (e *ReadLengthError) Error() string {
+	return fmt.Sprintf("read %d (%q) has length %d, need at least %d", e.Index, e.ID, e.Length, e.Required)
+}
+
+func (e *ReadLengthError) Unwrap() error {
+	return ErrReadTooShort
+}
@@ -0,0 +1,7 @@
+//Package fmindex answers exact substring queries over a genome or a set of
+//reads without scanning the text for every query. It builds the suffix array
+//of the text (with SA-IS, in linear time) and from it the Burrows-Wheeler
+//transform and FM-index, which count the occurrences of a pattern in time
+//proportional to the pattern's length, locate them, and find the reads whose
+//prefixes match suffixes of a query, i.e., the reads that overlap it.
+package fmindex
@@ -0,0 +1,144 @@
+package fmindex
+
+import "sort"
+
+// the Burrows-Wheeler transform (BWT) of a text lists, for every suffix in
+// suffix array order, the symbol just before it. It looks like a scramble, but
+// it holds a handy map: the occurrences of a symbol c appear in the same order
+// in the BWT as the suffixes starting with c appear in the suffix array. So if
+// we know the range of suffix array rows whose suffixes start with a pattern P,
+// counting the c's in the BWT above and inside that range gives the range of
+// rows starting with cP. Going through a pattern backwards, one symbol at a
+// time, we find all of its occurrences in as many steps as it has symbols,
+// however long the text is (backward search). The row ranges are all we need
+// for counting; the suffix array turns them into positions.
+
+//checkpointInterval is how far apart the running symbol counts of the BWT are
+//stored. Counting between checkpoints takes at most this many steps.
+const checkpointInterval = 32
+
+//Index is an FM-index of a text: its suffix array and Burrows-Wheeler
+//transform, with counts of every symbol at regular checkpoints along the BWT.
+//Rows count the empty suffix (at the end of the text), which comes first.
+type Index struct {
+	length      int    // length of the text
+	sa          []int  // suffix array, with the empty suffix in row 0
+	bwt         []byte // bwt[row] is the symbol before the suffix in that row
+	endRow      int    // the row of the whole text, which has no symbol before it
+	symbols     []byte // the distinct symbols of the text, in order
+	rank        [256]int
+	first       [256]int // first[c] is the first row whose suffix starts with c
+	checkpoints []int    // counts of every symbol in bwt[:i*checkpointInterval], symbol after symbol
+}
+
+//New builds the FM-index of text.
+func New(text string) *Index {
+	index := &Index{length: len(text)}
+	for c := range index.rank {
+		index.rank[c] = -1
+	}
+
+	// the suffix array, with the empty suffix (which sorts first) added
+	index.sa = append([]int{len(text)}, SuffixArray(text)...)
+
+	var counts [256]int
+	for i := 0; i < len(text); i++ {
+		counts[text[i]]++
+	}
+	row := 1 // after the empty suffix
+	for c := 0; c < 256; c++ {
+		if counts[c] > 0 {
+			index.rank[c] = len(index.symbols)
+			index.symbols = append(index.symbols, byte(c))
+			index.first[c] = row
+			row += counts[c]
+		}
+	}
+
+	index.bwt = make([]byte, len(index.sa))
+	for row, p := range index.sa {
+		if p == 0 {
+			index.endRow = row
+		} else {
+			index.bwt[row] = text[p-1]
+		}
+	}
+
+	sigma := len(index.symbols)
+	running := make([]int, sigma)
+	index.checkpoints = make([]int, 0, (len(index.bwt)/checkpointInterval+1)*sigma)
+	for row := range index.bwt {
+		if row%checkpointInterval == 0 {
+			index.checkpoints = append(index.checkpoints, running...)
+		}
+		if row != index.endRow {
+			running[index.rank[index.bwt[row]]]++
+		}
+	}
+	index.checkpoints = append(index.checkpoints, running...)
+	return index
+}
+
+//Len returns the length of the indexed text.
+func (index *Index) Len() int {
+	return index.length
+}
+
+//occurrences returns the number of times symbol c appears in the BWT above row.
+func (index *Index) occurrences(c byte, row int) int {
+	r := index.rank[c]
+	checkpoint := row / checkpointInterval
+	start := checkpoint * checkpointInterval
+	count := index.checkpoints[checkpoint*len(index.symbols)+r]
+	for _, b := range index.bwt[start:row] {
+		if b == c {
+			count++
+		}
+	}
+	// the whole text has no symbol before it, whatever bwt holds there
+	if start <= index.endRow && index.endRow < row && index.bwt[index.endRow] == c {
+		count--
+	}
+	return count
+}
+
+//extend takes the range of rows [lo, hi) whose suffixes start with some
+//pattern P and returns the range of rows starting with cP.
+func (index *Index) extend(c byte, lo, hi int) (int, int) {
+	if index.rank[c] < 0 {
+		return 0, 0
+	}
+	return index.first[c] + index.occurrences(c, lo), index.first[c] + index.occurrences(c, hi)
+}
+
+//rows returns the range of rows [lo, hi) whose suffixes start with pattern, by
+//backward search. The range is empty if pattern doesn't occur.
+func (index *Index) rows(pattern string) (int, int) {
+	lo, hi := 0, len(index.sa)
+	for i := len(pattern) - 1; i >= 0 && lo < hi; i-- {
+		lo, hi = index.extend(pattern[i], lo, hi)
+	}
+	return lo, hi
+}
+
+//Count returns the number of (possibly overlapping) occurrences of pattern in
+//the text. The empty pattern occurs at every position, and at the end.
+func (index *Index) Count(pattern string) int {
+	lo, hi := index.rows(pattern)
+	if hi < lo {
+		return 0
+	}
+	return hi - lo
+}
+
+//Locate returns the starting positions of the occurrences of pattern in the
+//text, in increasing order.
+func (index *Index) Locate(pattern string) []int {
+	lo, hi := index.rows(pattern)
+	positions := make([]int, 0, max(hi-lo, 0))
+	for row := lo; row < hi; row++ {
+		positions = append(positions, index.sa[row])
+	}
+	sort.Ints(positions)
+	return positions
+}
@@ -0,0 +1,196 @@
+package fmindex
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+//naiveSuffixArray sorts the suffixes of text directly.
+func naiveSuffixArray(text string) []int {
+	sa := make([]int, len(text))
+	for i := range sa {
+		sa[i] = i
+	}
+	sort.Slice(sa, func(a, b int) bool { return text[sa[a]:] < text[sa[b]:] })
+	return sa
+}
+
+//naiveLocate scans text for every occurrence of pattern.
+func naiveLocate(text, pattern string) []int {
+	positions := make([]int, 0)
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if text[i:i+len(pattern)] == pattern {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+func randomText(r *rand.Rand, n int, alphabet string) string {
+	symbols := make([]byte, n)
+	for i := range symbols {
+		symbols[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(symbols)
+}
+
+//suffixArrayTexts are texts known to trip up suffix sorting: repeats and runs
+//make SA-IS recurse, sometimes several levels deep.
+var suffixArrayTexts = []string{
+	"",
+	"A",
+	"AA",
+	"AB",
+	"BA",
+	"AAAAAAAAAAAAAAAAAAAA",
+	"ABABABABABABABABABAB",
+	"ABCABCABCABCABCABCA",
+	"ABAABAAABAAAAB",
+	"banana",
+	"mississippi",
+	"GATTACAGATTACAGATTACA",
+	"$ACGT$ACGA$CGTT$",
+	strings.Repeat("ACGTTGCA", 50),
+	strings.Repeat("A", 100) + "C" + strings.Repeat("A", 100),
+}
+
+func TestSuffixArrayKnown(t *testing.T) {
+	tests := map[string][]int{
+		"banana":      {5, 3, 1, 0, 4, 2},
+		"mississippi": {10, 7, 4, 1, 0, 9, 8, 6, 3, 5, 2},
+	}
+	for text, want := range tests {
+		if got := SuffixArray(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("SuffixArray(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestSuffixArray(t *testing.T) {
+	for _, text := range suffixArrayTexts {
+		if got, want := SuffixArray(text), naiveSuffixArray(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("SuffixArray(%q) = %v, want %v", text, got, want)
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, alphabet := range []string{"AB", "ACGT", "$ACGT", "abcdefghijklmnopqrstuvwxyz"} {
+		for trial := 0; trial < 200; trial++ {
+			text := randomText(r, r.Intn(300), alphabet)
+			if got, want := SuffixArray(text), naiveSuffixArray(text); !reflect.DeepEqual(got, want) {
+				t.Fatalf("SuffixArray(%q) = %v, want %v", text, got, want)
+			}
+		}
+	}
+}
+
+func TestIndexCountAndLocate(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	texts := append([]string{}, suffixArrayTexts...)
+	for trial := 0; trial < 20; trial++ {
+		// long enough for many checkpoints
+		texts = append(texts, randomText(r, 1000+r.Intn(1000), "ACGT"))
+	}
+
+	for _, text := range texts {
+		index := New(text)
+		if index.Len() != len(text) {
+			t.Fatalf("Len() = %d, want %d", index.Len(), len(text))
+		}
+
+		patterns := []string{"", "A", "AC", "ACGT", "N", "AN", text}
+		for trial := 0; trial < 30 && len(text) > 0; trial++ {
+			// substrings of the text, so most patterns occur
+			start := r.Intn(len(text))
+			end := start + 1 + r.Intn(min(12, len(text)-start))
+			patterns = append(patterns, text[start:end], randomText(r, 1+r.Intn(6), "ACGT"))
+		}
+
+		for _, pattern := range patterns {
+			want := naiveLocate(text, pattern)
+			if got := index.Count(pattern); got != len(want) {
+				t.Fatalf("text %.20q...: Count(%q) = %d, want %d", text, pattern, got, len(want))
+			}
+			if got := index.Locate(pattern); !reflect.DeepEqual(got, want) {
+				t.Fatalf("text %.20q...: Locate(%q) = %v, want %v", text, pattern, got, want)
+			}
+		}
+	}
+}
+
+func TestReadIndexLocate(t *testing.T) {
+	reads := []string{"ACGTACGT", "GTAC", "", "TTTT", "ACGT", "CGTA"}
+	index := NewReadIndex(reads)
+
+	for _, pattern := range []string{"", "A", "ACGT", "GTA", "TT", "TTTTT", "TACG", "CGTAC", "ACGTACGTACGT", "$", "T$G", "$ACGT"} {
+		want := make([]ReadHit, 0)
+		for r, read := range reads {
+			for _, p := range naiveLocate(read, pattern) {
+				want = append(want, ReadHit{Read: r, Position: p})
+			}
+		}
+		if pattern == "" {
+			// the empty pattern occurs in no read
+			want = want[:0]
+		}
+		if got := index.Locate(pattern); !reflect.DeepEqual(got, want) {
+			t.Errorf("Locate(%q) = %v, want %v", pattern, got, want)
+		}
+		if got := index.Count(pattern); got != len(want) {
+			t.Errorf("Count(%q) = %d, want %d", pattern, got, len(want))
+		}
+	}
+}
+
+//naiveSuffixPrefixOverlaps tries every suffix of query against every read.
+func naiveSuffixPrefixOverlaps(reads []string, query string, minLength int) []ReadOverlap {
+	overlaps := make([]ReadOverlap, 0)
+	for r, read := range reads {
+		for length := min(len(query), len(read)); length >= max(minLength, 1); length-- {
+			if strings.HasPrefix(read, query[len(query)-length:]) {
+				overlaps = append(overlaps, ReadOverlap{Read: r, Length: length})
+				break
+			}
+		}
+	}
+	sort.Slice(overlaps, func(a, b int) bool {
+		if overlaps[a].Length != overlaps[b].Length {
+			return overlaps[a].Length > overlaps[b].Length
+		}
+		return overlaps[a].Read < overlaps[b].Read
+	})
+	return overlaps
+}
+
+func TestSuffixPrefixOverlaps(t *testing.T) {
+	reads := []string{"CGTTA", "TACCA", "ACGT", "GTTACC", "ACGTTAC", "C"}
+	index := NewReadIndex(reads)
+
+	got := index.SuffixPrefixOverlaps("ACGTTAC", 2)
+	want := []ReadOverlap{{Read: 4, Length: 7}, {Read: 3, Length: 5}, {Read: 1, Length: 3}, {Read: 2, Length: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SuffixPrefixOverlaps(%q, 2) = %v, want %v", "ACGTTAC", got, want)
+	}
+
+	// random reads sampled from a short genome overlap each other a lot
+	r := rand.New(rand.NewSource(3))
+	genome := randomText(r, 300, "ACGT")
+	reads = make([]string, 100)
+	for i := range reads {
+		start := r.Intn(len(genome) - 10)
+		reads[i] = genome[start : start+10+r.Intn(min(40, len(genome)-start-10)+1)]
+	}
+	index = NewReadIndex(reads)
+	for _, query := range reads {
+		for _, minLength := range []int{0, 1, 5, 20} {
+			got := index.SuffixPrefixOverlaps(query, minLength)
+			want := naiveSuffixPrefixOverlaps(reads, query, minLength)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("SuffixPrefixOverlaps(%q, %d) = %v, want %v", query, minLength, got, want)
+			}
+		}
+	}
+}
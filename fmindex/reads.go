@@ -0,0 +1,118 @@
+package fmindex
+
+import (
+	"sort"
+	"strings"
+)
+
+// to index a collection of reads at once, we string them together, each one
+// after a separator symbol that occurs in no read, and index the result. A
+// pattern without the separator can't run from one read into the next, so its
+// occurrences are occurrences in the reads. And a pattern that does start with
+// the separator can only occur at the start of a read, which is how we find
+// the reads that begin with a given string.
+
+//Separator comes before every read in the text of a ReadIndex. Reads must not
+//contain it.
+const Separator = '$'
+
+//ReadIndex is an FM-index of a collection of reads.
+type ReadIndex struct {
+	Index  *Index
+	starts []int // starts[i] is where read i begins in the text
+}
+
+//ReadHit is an occurrence of a pattern in a read.
+type ReadHit struct {
+	Read     int
+	Position int
+}
+
+//ReadOverlap says that a suffix of Length symbols of some query is a prefix of
+//read Read.
+type ReadOverlap struct {
+	Read   int
+	Length int
+}
+
+//NewReadIndex builds an FM-index of a collection of reads (sequences without
+//the Separator symbol).
+func NewReadIndex(reads []string) *ReadIndex {
+	var text strings.Builder
+	starts := make([]int, len(reads))
+	for i, read := range reads {
+		text.WriteByte(Separator)
+		starts[i] = text.Len()
+		text.WriteString(read)
+	}
+	return &ReadIndex{Index: New(text.String()), starts: starts}
+}
+
+//readAt returns the read that the text position p falls in (or whose
+//separator it is).
+func (reads *ReadIndex) readAt(p int) int {
+	// the last read starting at or before p, counting its separator
+	return sort.Search(len(reads.starts), func(i int) bool {
+		return reads.starts[i] > p+1
+	}) - 1
+}
+
+//Count returns the number of occurrences of pattern in the reads. A pattern
+//with the Separator in it occurs in no read.
+func (reads *ReadIndex) Count(pattern string) int {
+	if pattern == "" || strings.IndexByte(pattern, Separator) >= 0 {
+		return 0
+	}
+	return reads.Index.Count(pattern)
+}
+
+//Locate returns the occurrences of pattern in the reads, ordered by read and
+//then by position in the read.
+func (reads *ReadIndex) Locate(pattern string) []ReadHit {
+	hits := make([]ReadHit, 0)
+	if pattern == "" || strings.IndexByte(pattern, Separator) >= 0 {
+		return hits
+	}
+	for _, p := range reads.Index.Locate(pattern) {
+		r := reads.readAt(p)
+		hits = append(hits, ReadHit{Read: r, Position: p - reads.starts[r]})
+	}
+	return hits
+}
+
+//SuffixPrefixOverlaps returns every read that starts with a suffix of query at
+//least minLength symbols long, each with its longest such suffix, longest
+//first (by read index on a tie). A read equal to a whole suffix of query, or to
+//query itself, counts. Finding them takes one backward search over query, plus
+//the time to list the reads.
+func (reads *ReadIndex) SuffixPrefixOverlaps(query string, minLength int) []ReadOverlap {
+	if minLength < 1 {
+		minLength = 1
+	}
+	longest := make(map[int]int)
+	index := reads.Index
+	lo, hi := 0, len(index.sa)
+	// suffixes of query, shortest first, so each read ends up with its longest
+	for i := len(query) - 1; i >= 0 && lo < hi; i-- {
+		lo, hi = index.extend(query[i], lo, hi)
+		if length := len(query) - i; length >= minLength {
+			// the rows of the suffix right after a separator are read starts
+			startLo, startHi := index.extend(Separator, lo, hi)
+			for row := startLo; row < startHi; row++ {
+				longest[reads.readAt(index.sa[row])] = length
+			}
+		}
+	}
+
+	overlaps := make([]ReadOverlap, 0, len(longest))
+	for r, length := range longest {
+		overlaps = append(overlaps, ReadOverlap{Read: r, Length: length})
+	}
+	sort.Slice(overlaps, func(a, b int) bool {
+		if overlaps[a].Length != overlaps[b].Length {
+			return overlaps[a].Length > overlaps[b].Length
+		}
+		return overlaps[a].Read < overlaps[b].Read
+	})
+	return overlaps
+}
@@ -0,0 +1,173 @@
+package fmindex
+
+// a suffix array lists the suffixes of a text in alphabetical order (by where
+// they start). Sorting them directly takes O(n log n) comparisons that can each
+// take O(n) time. SA-IS (Nong, Zhang and Chan, 2009) sorts them in linear time
+// with a neat trick: call a suffix S-type if it is smaller than the suffix after
+// it and L-type otherwise, and LMS (leftmost S) if it is S-type and the suffix
+// before it is L-type. Once the LMS suffixes are in order, one pass from left to
+// right puts every L-type suffix in place and one pass from right to left every
+// S-type suffix (this is "induced sorting"). And putting the LMS suffixes in
+// order is the same problem on a text half as long at most, so we recurse.
+
+//SuffixArray returns the suffix array of text: the starting positions of its
+//suffixes in alphabetical order.
+func SuffixArray(text string) []int {
+	// rank the symbols 1, 2, ... and end the text with a sentinel 0, smaller than
+	// every symbol, which the algorithm needs
+	var present [256]bool
+	for i := 0; i < len(text); i++ {
+		present[text[i]] = true
+	}
+	var rank [256]int
+	k := 1
+	for c := 0; c < 256; c++ {
+		if present[c] {
+			rank[c] = k
+			k++
+		}
+	}
+	s := make([]int, len(text)+1)
+	for i := 0; i < len(text); i++ {
+		s[i] = rank[text[i]]
+	}
+	// the sentinel's suffix comes first; the rest is the suffix array of text
+	return sais(s, k)[1:]
+}
+
+//sais returns the suffix array of s, whose symbols are 0 ... k-1 and whose last
+//symbol is a 0 that appears nowhere else.
+func sais(s []int, k int) []int {
+	n := len(s)
+	sa := make([]int, n)
+	if n == 1 {
+		return sa
+	}
+
+	// sType[i] is true if suffix i is S-type
+	sType := make([]bool, n)
+	sType[n-1] = true
+	for i := n - 2; i >= 0; i-- {
+		sType[i] = s[i] < s[i+1] || (s[i] == s[i+1] && sType[i+1])
+	}
+	isLMS := func(i int) bool {
+		return i > 0 && sType[i] && !sType[i-1]
+	}
+
+	// suffixes starting with the same symbol form a bucket of the suffix array
+	bucketSizes := make([]int, k)
+	for _, c := range s {
+		bucketSizes[c]++
+	}
+	bucketHeads := func() []int {
+		heads := make([]int, k)
+		sum := 0
+		for c := range heads {
+			heads[c] = sum
+			sum += bucketSizes[c]
+		}
+		return heads
+	}
+	bucketTails := func() []int {
+		tails := make([]int, k)
+		sum := 0
+		for c := range tails {
+			sum += bucketSizes[c]
+			tails[c] = sum
+		}
+		return tails
+	}
+
+	// induce sorts every suffix, given the LMS suffixes in order
+	induce := func(lms []int) {
+		for i := range sa {
+			sa[i] = -1
+		}
+		// the LMS suffixes go at the ends of their buckets
+		tails := bucketTails()
+		for i := len(lms) - 1; i >= 0; i-- {
+			p := lms[i]
+			tails[s[p]]--
+			sa[tails[s[p]]] = p
+		}
+		// left to right: an L-type suffix goes after the suffix following it
+		heads := bucketHeads()
+		for i := 0; i < n; i++ {
+			if j := sa[i] - 1; sa[i] > 0 && !sType[j] {
+				sa[heads[s[j]]] = j
+				heads[s[j]]++
+			}
+		}
+		// right to left: so does an S-type suffix, filling buckets from the end
+		tails = bucketTails()
+		for i := n - 1; i >= 0; i-- {
+			if j := sa[i] - 1; sa[i] > 0 && sType[j] {
+				tails[s[j]]--
+				sa[tails[s[j]]] = j
+			}
+		}
+	}
+
+	// a first round of induced sorting, from the LMS suffixes in any order, sorts
+	// the LMS substrings (from one LMS position to the next)
+	lms := make([]int, 0)
+	for i := 1; i < n; i++ {
+		if isLMS(i) {
+			lms = append(lms, i)
+		}
+	}
+	induce(lms)
+
+	// name the LMS substrings by their rank, equal substrings getting equal names
+	names := make([]int, n)
+	name, previous := 0, -1
+	for _, p := range sa {
+		if !isLMS(p) {
+			continue
+		}
+		if previous >= 0 && !equalLMSSubstrings(s, sType, isLMS, previous, p) {
+			name++
+		}
+		names[p] = name
+		previous = p
+	}
+
+	// the LMS suffixes sort like the string of their names
+	reduced := make([]int, len(lms))
+	for i, p := range lms {
+		reduced[i] = names[p]
+	}
+	var reducedSA []int
+	if name+1 < len(lms) {
+		reducedSA = sais(reduced, name+1)
+	} else {
+		// every name is different, so the names are the ranks
+		reducedSA = make([]int, len(lms))
+		for i, c := range reduced {
+			reducedSA[c] = i
+		}
+	}
+	sorted := make([]int, len(lms))
+	for i, r := range reducedSA {
+		sorted[i] = lms[r]
+	}
+
+	// a second round from the LMS suffixes in order sorts everything
+	induce(sorted)
+	return sa
+}
+
+//equalLMSSubstrings reports whether the LMS substrings of s starting at a and b
+//(running up to and including the next LMS position) are the same.
+func equalLMSSubstrings(s []int, sType []bool, isLMS func(int) bool, a, b int) bool {
+	n := len(s)
+	for i := 0; a+i < n && b+i < n; i++ {
+		if s[a+i] != s[b+i] || sType[a+i] != sType[b+i] {
+			return false
+		}
+		if i > 0 && (isLMS(a+i) || isLMS(b+i)) {
+			return isLMS(a+i) && isLMS(b+i)
+		}
+	}
+	return false
+}
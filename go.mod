@@ -0,0 +1,3 @@
+module github.com/kaushikvemparala/Walker
+
+go 1.21
@@ -0,0 +1,7 @@
+//Package index builds the indices the overlap assemblers use to find reads
+//that might overlap a given one. The prefix and suffix indices map each prefix
+//(or suffix) of a fixed length, packed two bits per base (see package packed),
+//to the positions of the reads that have it; the minimizer index samples
+//k-mers from all along the reads, so that it finds overlaps anywhere in a read,
+//errors and all, along with where the reads line up.
+package index
@@ -0,0 +1,228 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/packed"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// an index used to be a map from strings to reads, which hashes a whole
+// indexLength-long string for every lookup. Ends of up to 32 bases pack into
+// a single number (see packed.Kmer), which is a much cheaper key, and looking up
+// every position of a read in turn can roll the number along the read instead
+// of packing each position from scratch (see Matches). Longer ends pack into
+// one word per 32 bases (see packed.LongKmer), a quarter of the string; to look
+// them up along a read we pack the read once and only put an end together
+// where its first 32 bases are the start of some indexed end.
+
+//EndIndex maps the prefixes (or suffixes) of length Length of a collection of
+//reads to the reads that have them.
+type EndIndex struct {
+	Length int
+	short  map[packed.Kmer][]int     // ends of at most packed.MaxKmerLength bases
+	long   map[packed.LongKmer][]int // longer ends
+	heads  map[packed.Kmer]bool      // the first packed.MaxKmerLength bases of the longer ends
+	other  map[string][]int          // ends with symbols other than A, C, G, T
+}
+
+//NewEndIndex returns an empty index of ends of the given length.
+func NewEndIndex(length int) *EndIndex {
+	return &EndIndex{
+		Length: length,
+		short:  make(map[packed.Kmer][]int),
+		long:   make(map[packed.LongKmer][]int),
+		heads:  make(map[packed.Kmer]bool),
+		other:  make(map[string][]int),
+	}
+}
+
+//Add files read under end, which must be Length symbols long.
+func (index *EndIndex) Add(end string, read int) {
+	if !seqio.ValidDNAString(end) {
+		index.other[end] = append(index.other[end], read)
+		return
+	}
+	if index.Length <= packed.MaxKmerLength {
+		kmer, _ := packed.EncodeKmer(end)
+		index.short[kmer] = append(index.short[kmer], read)
+		return
+	}
+	kmer, _ := packed.EncodeLongKmer(end)
+	head, _ := packed.EncodeKmer(end[:packed.MaxKmerLength])
+	index.long[kmer] = append(index.long[kmer], read)
+	index.heads[head] = true
+}
+
+//Lookup returns the reads filed under end, in the order they were added.
+func (index *EndIndex) Lookup(end string) []int {
+	if !seqio.ValidDNAString(end) {
+		return index.other[end]
+	}
+	if index.Length <= packed.MaxKmerLength {
+		kmer, _ := packed.EncodeKmer(end)
+		return index.short[kmer]
+	}
+	kmer, _ := packed.EncodeLongKmer(end)
+	return index.long[kmer]
+}
+
+//EndMatch is a place in a text where some indexed ends occur: Reads are the
+//reads filed under the Length symbols starting at Position.
+type EndMatch struct {
+	Position int
+	Reads    []int
+}
+
+//Matches looks up every Length-long substring of text and returns the ones
+//that are in the index, in order of position. It rolls the packed k-mer along
+//text (or, for ends longer than packed.MaxKmerLength, reads them out of the
+//packed text), so it costs about one map lookup per position, without hashing
+//a string at each one.
+func (index *EndIndex) Matches(text string) []EndMatch {
+	matches := make([]EndMatch, 0)
+	if len(index.other) > 0 {
+		for p := 0; p+index.Length <= len(text); p++ {
+			if reads, ok := index.other[text[p:p+index.Length]]; ok {
+				matches = append(matches, EndMatch{Position: p, Reads: reads})
+			}
+		}
+	}
+	// an end that packs can't be in other, so these don't repeat any
+	if index.Length <= packed.MaxKmerLength {
+		kmers, err := packed.NewKmerIterator(text, index.Length)
+		if err != nil {
+			// an index of empty ends has nothing to look up
+			return matches
+		}
+		for kmers.Next() {
+			if reads, ok := index.short[kmers.Kmer()]; ok {
+				matches = append(matches, EndMatch{Position: kmers.Position(), Reads: reads})
+			}
+		}
+	} else {
+		// pack each stretch of A, C, G, T once and read the ends out of it
+		for _, run := range dnaRuns(text, index.Length) {
+			sequence, _ := packed.Pack(text[run[0]:run[1]])
+			for p := 0; p+index.Length <= sequence.Len(); p++ {
+				if !index.heads[sequence.Kmer(p, packed.MaxKmerLength)] {
+					continue
+				}
+				if reads, ok := index.long[sequence.LongKmer(p, index.Length)]; ok {
+					matches = append(matches, EndMatch{Position: run[0] + p, Reads: reads})
+				}
+			}
+		}
+	}
+	if len(index.other) > 0 {
+		sort.Slice(matches, func(a, b int) bool {
+			return matches[a].Position < matches[b].Position
+		})
+	}
+	return matches
+}
+
+//dnaRuns returns the start and end of every stretch of text at least length
+//symbols long made up of A, C, G, T only, in order.
+func dnaRuns(text string, length int) [][2]int {
+	runs := make([][2]int, 0, 1)
+	start := 0
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && (text[i] == 'A' || text[i] == 'C' || text[i] == 'G' || text[i] == 'T') {
+			continue
+		}
+		if i-start >= length {
+			runs = append(runs, [2]int{start, i})
+		}
+		start = i + 1
+	}
+	return runs
+}
+
+//BuildPrefixIndex takes a collection of reads (of arbitrary length bigger than prefix length)
+//and a prefix length.
+//It returns an index of the prefixes of length prefixLength of the reads, or
+//a *ReadLengthError if a read is too short.
+func BuildPrefixIndex(reads []seqio.Read, prefixLength int) (*EndIndex, error) {
+	index := NewEndIndex(prefixLength)
+
+	//populate our index
+	for i := range reads {
+		read := reads[i].Sequence
+		if len(read) < prefixLength {
+			return nil, &walker.ReadLengthError{Index: i, ID: reads[i].ID, Length: len(read), Required: prefixLength}
+		}
+		index.Add(read[:prefixLength], i)
+		if i%100000 == 0 {
+			fmt.Println("Update: We have indexed", i, "prefixes.")
+		}
+	}
+	return index, nil
+}
+
+//BuildSuffixIndex takes a collection of reads and a suffix length.
+//It returns an index of the suffixes of length suffixLength of the reads, or
+//a *ReadLengthError if a read is too short.
+func BuildSuffixIndex(reads []seqio.Read, suffixLength int) (*EndIndex, error) {
+	index := NewEndIndex(suffixLength)
+
+	//populate our index
+	for i := range reads {
+		read := reads[i].Sequence
+		if len(read) < suffixLength {
+			return nil, &walker.ReadLengthError{Index: i, ID: reads[i].ID, Length: len(read), Required: suffixLength}
+		}
+		n := len(read)
+		index.Add(read[n-suffixLength:], i) // we want suffix of length suffixLength
+		if i%100000 == 0 {
+			fmt.Println("Update: We have indexed", i, "suffixes.")
+		}
+	}
+	return index, nil
+}
+
+//IndexReadStream drains a stream of reads, building the read collection and its
+//prefix and suffix indices of length indexLength in the same pass, so the reads
+//are only read once. Duplicate reads are collapsed as CollectReads does (adding
+//up multiplicities, keeping the first ID), by checking the reads already filed
+//under the same prefix, so no separate map of sequences is needed.
+//It returns a *ReadLengthError if a read is too short to index.
+func IndexReadStream(stream seqio.ReadStream, indexLength int) ([]seqio.Read, *EndIndex, *EndIndex, error) {
+	defer stream.Close()
+	reads := make([]seqio.Read, 0)
+	prefixIndex := NewEndIndex(indexLength)
+	suffixIndex := NewEndIndex(indexLength)
+
+	for stream.Next() {
+		read := stream.Read()
+		if read.Multiplicity < 1 {
+			read.Multiplicity = 1
+		}
+		n := len(read.Sequence)
+		if n < indexLength {
+			return nil, nil, nil, &walker.ReadLengthError{Index: len(reads), ID: read.ID, Length: n, Required: indexLength}
+		}
+		prefix := read.Sequence[:indexLength]
+		duplicate := false
+		for _, i := range prefixIndex.Lookup(prefix) {
+			if reads[i].Sequence == read.Sequence {
+				reads[i].Multiplicity += read.Multiplicity
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		i := len(reads)
+		reads = append(reads, read)
+		prefixIndex.Add(prefix, i)
+		suffixIndex.Add(read.Sequence[n-indexLength:], i)
+		if (i+1)%100000 == 0 {
+			fmt.Println("Update: We have indexed", i+1, "reads.")
+		}
+	}
+	return reads, prefixIndex, suffixIndex, stream.Err()
+}
@@ -0,0 +1,179 @@
+package index
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	walker "github.com/kaushikvemparala/Walker"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+// the prefix and suffix indices only see the first or last few symbols of a
+// read, so a single error there hides every overlap of that end. Instead, we
+// can index k-mers from all along the read. Indexing every k-mer would take a
+// lot of memory, so we only keep a sample of them, chosen so that two reads
+// that share a stretch of sequence keep the same k-mers from it: in every
+// window of w consecutive k-mers, we keep the smallest (according to a hash, so
+// that AAAA... isn't always the smallest). These are the minimizers of the read.
+// Two reads that overlap share the minimizers of their overlap (the ones free of
+// errors, anyway), and the positions of the shared minimizers say where the
+// reads line up.
+
+//Minimizer is a k-mer chosen to stand for the windows of a sequence, with its
+//hash and where it starts in the sequence.
+type Minimizer struct {
+	Hash     uint64
+	Position int
+}
+
+//MinimizerHit is an occurrence of a minimizer in the indexed reads.
+type MinimizerHit struct {
+	Read     int
+	Position int
+}
+
+//MinimizerIndex maps the minimizers of a collection of reads (for windows of W
+//k-mers of length K) to where they occur.
+type MinimizerIndex struct {
+	W, K int
+	Hits map[uint64][]MinimizerHit
+}
+
+//CandidatePair is a read (Target) that seems to overlap another sequence (the
+//query), because the two share Shared minimizers on (about) the same diagonal.
+//Diagonal is the estimated offset of the target in the query: the target starts
+//at position Diagonal of the query (to the left of its start if Diagonal is
+//negative). Overlap is the resulting estimate of the overlap length.
+type CandidatePair struct {
+	Target   int
+	Shared   int
+	Diagonal int
+	Overlap  int
+}
+
+//kmerHash scrambles a k-mer into a number, so that minimizers are spread evenly
+//over the k-mers rather than favoring ones like AAAA...
+func kmerHash(pattern string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(pattern))
+	return hash.Sum64()
+}
+
+//Minimizers returns the (w,k) minimizers of a sequence, in order of position:
+//for every window of w consecutive k-mers, the k-mer with the smallest hash (the
+//leftmost one on a tie), listed once even if it stands for several windows. A
+//sequence shorter than w k-mers is one window.
+func Minimizers(sequence string, w, k int) []Minimizer {
+	minimizers := make([]Minimizer, 0)
+	numKmers := len(sequence) - k + 1
+	if numKmers <= 0 {
+		return minimizers
+	}
+	hashes := make([]uint64, numKmers)
+	for i := range hashes {
+		hashes[i] = kmerHash(sequence[i : i+k])
+	}
+
+	for start := 0; start == 0 || start+w <= numKmers; start++ {
+		best := start
+		for i := start; i < start+w && i < numKmers; i++ {
+			if hashes[i] < hashes[best] {
+				best = i
+			}
+		}
+		if len(minimizers) == 0 || minimizers[len(minimizers)-1].Position != best {
+			minimizers = append(minimizers, Minimizer{Hash: hashes[best], Position: best})
+		}
+	}
+	return minimizers
+}
+
+//BuildMinimizerIndex takes a collection of reads and indexes their (w,k)
+//minimizers (see Minimizers). It returns a *walker.ParameterError if w or k
+//isn't positive and a *ReadLengthError if a read is shorter than k.
+func BuildMinimizerIndex(reads []seqio.Read, w, k int) (*MinimizerIndex, error) {
+	if w < 1 {
+		return nil, &walker.ParameterError{Name: "w", Value: w, Reason: "must be positive"}
+	}
+	if k < 1 {
+		return nil, &walker.ParameterError{Name: "k", Value: k, Reason: "must be positive"}
+	}
+	index := &MinimizerIndex{W: w, K: k, Hits: make(map[uint64][]MinimizerHit)}
+	for i := range reads {
+		read := reads[i].Sequence
+		if len(read) < k {
+			return nil, &walker.ReadLengthError{Index: i, ID: reads[i].ID, Length: len(read), Required: k}
+		}
+		for _, minimizer := range Minimizers(read, w, k) {
+			index.Hits[minimizer.Hash] = append(index.Hits[minimizer.Hash], MinimizerHit{Read: i, Position: minimizer.Position})
+		}
+		if i%100000 == 0 {
+			fmt.Println("Update: We have indexed the minimizers of", i, "reads.")
+		}
+	}
+	return index, nil
+}
+
+//FindCandidates returns the indexed reads that share at least minShared
+//minimizers with query on about the same diagonal, i.e., whose shared
+//minimizers sit at positions that differ by amounts within band of each other
+//(insertions and deletions shift the diagonal a little along an overlap). The
+//reads are given the lengths of reads, which must be the collection the index
+//was built from. Candidates come best first: most shared minimizers, then by
+//read index.
+func (index *MinimizerIndex) FindCandidates(query string, reads []seqio.Read, minShared, band int) []CandidatePair {
+	// the diagonals of the hits in every read
+	diagonals := make(map[int][]int)
+	for _, minimizer := range Minimizers(query, index.W, index.K) {
+		for _, hit := range index.Hits[minimizer.Hash] {
+			diagonals[hit.Read] = append(diagonals[hit.Read], minimizer.Position-hit.Position)
+		}
+	}
+
+	candidates := make([]CandidatePair, 0)
+	for target, targetDiagonals := range diagonals {
+		if len(targetDiagonals) < minShared {
+			continue
+		}
+		// the stretch of diagonals no wider than band holding the most hits
+		sort.Ints(targetDiagonals)
+		bestStart, bestCount := 0, 0
+		end := 0
+		for start := range targetDiagonals {
+			for end < len(targetDiagonals) && targetDiagonals[end]-targetDiagonals[start] <= band {
+				end++
+			}
+			if end-start > bestCount {
+				bestStart, bestCount = start, end-start
+			}
+		}
+		if bestCount < minShared {
+			continue
+		}
+		diagonal := targetDiagonals[bestStart+bestCount/2]
+
+		// the overlap runs from the later start to the earlier end
+		overlapStart, overlapEnd := diagonal, diagonal+len(reads[target].Sequence)
+		if overlapStart < 0 {
+			overlapStart = 0
+		}
+		if overlapEnd > len(query) {
+			overlapEnd = len(query)
+		}
+		candidates = append(candidates, CandidatePair{
+			Target:   target,
+			Shared:   bestCount,
+			Diagonal: diagonal,
+			Overlap:  overlapEnd - overlapStart,
+		})
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].Shared != candidates[b].Shared {
+			return candidates[a].Shared > candidates[b].Shared
+		}
+		return candidates[a].Target < candidates[b].Target
+	})
+	return candidates
+}
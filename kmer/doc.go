@@ -0,0 +1,4 @@
+//Package kmer counts k-mers and compares strings by the k-mers they share.
+//It also has the usual small sequence utilities: pattern counting, skew
+//arrays and reverse complements.
+package kmer
@@ -0,0 +1,100 @@
+package kmer
+
+import "math"
+
+// how many k-mers do two copies of the same string share, if one copy has errors?
+// a k-mer survives if none of its k symbols was substituted or deleted and no
+// symbol was inserted between them. neighboring k-mers share most of their
+// symbols, so they survive or die together, which makes the count vary much more
+// than if every k-mer were a separate coin flip. we can work all of this out
+// exactly instead of simulating a random string every time we want to know.
+
+//ErrorModel gives the rates of the errors separating two copies of a string:
+//the chance that a symbol is substituted or deleted, and the chance that a
+//symbol is inserted after a given symbol.
+type ErrorModel struct {
+	Substitution float64
+	Insertion    float64
+	Deletion     float64
+}
+
+//SubstitutionModel returns an error model with substitutions only, the kind of
+//errors MutateDNAString makes.
+func SubstitutionModel(errorRate float64) ErrorModel {
+	return ErrorModel{Substitution: errorRate}
+}
+
+//survival returns the chance that a stretch of span symbols (and the span-1
+//gaps between them) comes through the errors untouched.
+func (model ErrorModel) survival(span int) float64 {
+	symbol := 1.0 - model.Substitution - model.Deletion
+	gap := 1.0 - model.Insertion
+	if symbol <= 0.0 {
+		return 0.0
+	}
+	return math.Pow(symbol, float64(span)) * math.Pow(gap, float64(span-1))
+}
+
+//SharedKmerStatistics returns the mean and variance of the number of k-mers a
+//random string of length stringLength shares with a copy of itself that has
+//errors according to model (as counted by CountSharedKmers). Two kinds of k-mers
+//are shared: the ones that survive the errors, and the damaged ones that happen
+//to match some other damaged k-mer by chance, which matters for small k.
+func SharedKmerStatistics(stringLength int, model ErrorModel, k int) (float64, float64) {
+	numKmers := stringLength - k + 1
+	if numKmers <= 0 || k < 1 {
+		return 0.0, 0.0
+	}
+	n := float64(numKmers)
+	p := model.survival(k)
+
+	// surviving k-mers: the mean is easy, and two k-mers d apart (d < k) both
+	// survive when their k+d symbols do, so they are positively correlated
+	mean := n * p
+	variance := n * p * (1.0 - p)
+	for d := 1; d < k && d < numKmers; d++ {
+		covariance := model.survival(k+d) - p*p
+		variance += 2.0 * float64(numKmers-d) * covariance
+	}
+
+	// damaged k-mers matching one another by chance: the n(1-p) damaged k-mers
+	// of each copy are scattered over the 4^k possible k-mers, about
+	// lambda = n(1-p)/4^k of them on each one, and a k-mer shared j times needs
+	// at least j copies in both strings. there are few of these when 4^k is
+	// much larger than the string, so we treat their number as Poisson (variance
+	// equal to the mean); for tiny k the model is only rough
+	numPatterns := math.Pow(4.0, float64(k))
+	lambda := n * (1.0 - p) / numPatterns
+	chance := numPatterns * expectedPoissonMin(lambda)
+	mean += chance
+	variance += chance
+
+	return mean, variance
+}
+
+//SharedKmerThreshold returns the number of shared k-mers that two copies of a
+//string of length stringLength, separated by errors according to model, reach
+//with probability confidence (using a normal approximation): fewer shared
+//k-mers than this suggests the strings aren't copies at all. A confidence of
+//0.95 means we wrongly reject 1 in 20 true overlaps.
+func SharedKmerThreshold(stringLength int, model ErrorModel, k int, confidence float64) float64 {
+	mean, variance := SharedKmerStatistics(stringLength, model, k)
+	// z is the confidence quantile of the standard normal distribution
+	z := math.Sqrt2 * math.Erfinv(2.0*confidence-1.0)
+	return mean - z*math.Sqrt(variance)
+}
+
+//expectedPoissonMin returns the expected value of min(X, Y) for independent
+//Poisson random variables X and Y with mean lambda, the sum over j >= 1 of
+//P(X >= j)^2.
+func expectedPoissonMin(lambda float64) float64 {
+	sum := 0.0
+	term := math.Exp(-lambda) // P(X = 0)
+	atLeast := 1.0 - term     // P(X >= 1)
+	for j := 1; atLeast > 1e-12; j++ {
+		sum += atLeast * atLeast
+		term *= lambda / float64(j) // P(X = j)
+		atLeast -= term
+	}
+	return sum
+}
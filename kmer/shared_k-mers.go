@@ -0,0 +1,321 @@
+package kmer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kaushikvemparala/Walker/fmindex"
+	"github.com/kaushikvemparala/Walker/packed"
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+//ExpectedSharedkmers returns the number of k-mers we expect a random string of
+//length stringLength to share with a copy of itself in which each symbol was
+//substituted with probability errorRate (see SharedKmerStatistics). We used to
+//find this by mutating one random string, which gave a different answer every
+//time.
+func ExpectedSharedkmers(stringLength int, errorRate float64, k int) int {
+	mean, _ := SharedKmerStatistics(stringLength, SubstitutionModel(errorRate), k)
+	return int(math.Round(mean))
+}
+
+//CountSharedKmers returns the number of k-mers str1 and str2 have in common,
+//counting a k-mer as often as it appears in both. For DNA it counts packed
+//k-mers (see KmerCounts and LongKmerCounts), which is the same count found
+//faster.
+func CountSharedKmers(str1, str2 string, k int) int {
+	count := 0
+
+	if k >= 1 && seqio.ValidDNAString(str1) && seqio.ValidDNAString(str2) {
+		// k is in range, so these can't fail
+		if k <= packed.MaxKmerLength {
+			counts1, _ := KmerCounts(str1, k)
+			counts2, _ := KmerCounts(str2, k)
+			for kmer, count1 := range counts1 {
+				count += Min2(count1, counts2[kmer])
+			}
+			return count
+		}
+		counts1, _ := LongKmerCounts(str1, k)
+		counts2, _ := LongKmerCounts(str2, k)
+		for kmer, count1 := range counts1 {
+			count += Min2(count1, counts2[kmer])
+		}
+		return count
+	}
+
+	freqMap1 := FrequencyMap(str1, k)
+	freqMap2 := FrequencyMap(str2, k)
+
+	for pattern := range freqMap1 {
+		// just take the minimum
+		count += Min2(freqMap1[pattern], freqMap2[pattern])
+	}
+	return count
+}
+
+func Min2(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func FrequentWords(text string, k int) []string {
+	freqPatterns := make([]string, 0)
+
+	freqMap := FrequencyMap(text, k)
+
+	// find the max value of frequency map
+	m := MaxMap(freqMap)
+
+	// what achieves the max?
+	for pattern, val := range freqMap {
+		if val == m {
+			// frequent pattern found! append it to our list
+			freqPatterns = append(freqPatterns, pattern)
+		}
+	}
+
+	return freqPatterns
+}
+
+func MaxMap(freqMap map[string]int) int {
+	m := 0
+
+	// range through map, and if something has higher value, update m!
+	for pattern := range freqMap {
+		if freqMap[pattern] > m {
+			m = freqMap[pattern]
+		}
+	}
+	// if all values in map were negative integers, this would return 0.
+	// challenge: fix this bug so that it finds max value of any map of strings to ints.
+
+	return m
+}
+
+//FrequencyMap takes a string text and an integer k. It produces a map
+//of all k-mers in the string to their number of occurrences.
+func FrequencyMap(text string, k int) map[string]int {
+	// map declaration is analogous to slices
+	// (we don't need to give an initial length)
+	freq := make(map[string]int)
+	n := len(text)
+	for i := 0; i < n-k+1; i++ {
+		pattern := text[i : i+k]
+		// if freqMap[pattern] doesn't exist, create it.  How do we do this?
+		/*
+		   // approach #1
+		   _, exists := freq[pattern]
+		   if exists == false {
+		     // create this value
+		     freqMap[pattern] = 1
+		   } else {
+		     // we already have a value in the map
+		     freqMap[pattern]++
+		   }
+		*/
+		// approach #2
+		// this says, if freqMap[pattern] exists, add one to it
+		// if freqMap[pattern] doesn't exist, create it with a default value of 0, and add 1.
+		freq[pattern]++
+	}
+	return freq
+}
+
+//KmerCounts is FrequencyMap with every k-mer packed into a number (see
+//packed.Kmer). A number is a cheaper map key than a string, and rolling it
+//along text takes constant time per k-mer. K-mers with symbols other than A, C,
+//G, T are left out. It returns a *walker.ParameterError unless
+//1 <= k <= packed.MaxKmerLength.
+func KmerCounts(text string, k int) (map[packed.Kmer]int, error) {
+	kmers, err := packed.NewKmerIterator(text, k)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[packed.Kmer]int)
+	for kmers.Next() {
+		counts[kmers.Kmer()]++
+	}
+	return counts, nil
+}
+
+//LongKmerCounts is KmerCounts for k-mers of any length, packed one word per
+//packed.MaxKmerLength bases (see packed.LongKmer). It returns a
+//*walker.ParameterError unless k is positive.
+func LongKmerCounts(text string, k int) (map[packed.LongKmer]int, error) {
+	kmers, err := packed.NewLongKmerIterator(text, k)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[packed.LongKmer]int)
+	for kmers.Next() {
+		counts[kmers.Kmer()]++
+	}
+	return counts, nil
+}
+
+//CountReadKmers counts the packed k-mers of a collection of reads (see
+//KmerCounts), each read counting as many times as its multiplicity. Reads
+//shorter than k or with symbols other than A, C, G, T are skipped. If
+//doubleStranded is true, every read is counted on both strands (as if its
+//reverse complement were a read too), so a k-mer and its reverse complement
+//always have the same count. It returns a *walker.ParameterError unless
+//1 <= k <= packed.MaxKmerLength; see CountReadLongKmers for longer k-mers.
+func CountReadKmers(reads []seqio.Read, k int, doubleStranded bool) (map[packed.Kmer]int, error) {
+	// k is the same for every read, so checking it once will do
+	counts, err := KmerCounts("", k)
+	if err != nil {
+		return nil, err
+	}
+	countReads(reads, k, doubleStranded, func(text string, multiplicity int) {
+		kmers, _ := packed.NewKmerIterator(text, k)
+		for kmers.Next() {
+			counts[kmers.Kmer()] += multiplicity
+		}
+	})
+	return counts, nil
+}
+
+//CountReadLongKmers is CountReadKmers for k-mers of any length (see
+//LongKmerCounts). It returns a *walker.ParameterError unless k is positive.
+func CountReadLongKmers(reads []seqio.Read, k int, doubleStranded bool) (map[packed.LongKmer]int, error) {
+	counts, err := LongKmerCounts("", k)
+	if err != nil {
+		return nil, err
+	}
+	countReads(reads, k, doubleStranded, func(text string, multiplicity int) {
+		kmers, _ := packed.NewLongKmerIterator(text, k)
+		for kmers.Next() {
+			counts[kmers.Kmer()] += multiplicity
+		}
+	})
+	return counts, nil
+}
+
+//countReads hands every read that has k-mers to count to add (and, if
+//doubleStranded, its reverse complement too), along with its multiplicity.
+func countReads(reads []seqio.Read, k int, doubleStranded bool, add func(text string, multiplicity int)) {
+	for i, read := range reads {
+		if len(read.Sequence) < k || !seqio.ValidDNAString(read.Sequence) {
+			continue
+		}
+		multiplicity := read.Multiplicity
+		if multiplicity < 1 {
+			multiplicity = 1
+		}
+		add(read.Sequence, multiplicity)
+		if doubleStranded {
+			add(ReverseComplement(read.Sequence), multiplicity)
+		}
+		if (i+1)%100000 == 0 {
+			fmt.Println("Update: we have counted k-mers in", i+1, "reads.")
+		}
+	}
+}
+
+//PatternCount returns the number of (possibly overlapping) occurrences of
+//pattern in text. It scans text once; to count many patterns in the same long
+//text, build an fmindex.Index of it and use PatternCountIndex instead.
+func PatternCount(pattern, text string) int {
+	hits := StartingIndices(pattern, text)
+	return len(hits)
+}
+
+//PatternCountIndex is PatternCount for a text we have an FM-index of. It takes
+//as many steps as pattern has symbols, however long the text is.
+func PatternCountIndex(pattern string, index *fmindex.Index) int {
+	return index.Count(pattern)
+}
+
+//StartingIndicesIndex is StartingIndices for a text we have an FM-index of.
+//The positions come in increasing order, as from StartingIndices.
+func StartingIndicesIndex(pattern string, index *fmindex.Index) []int {
+	return index.Locate(pattern)
+}
+
+func StartingIndices(pattern, text string) []int {
+	hits := make([]int, 0)
+
+	// append every starting position of pattern that we find in text
+
+	n := len(text)
+	k := len(pattern)
+
+	for i := 0; i < n-k+1; i++ {
+		if text[i:i+k] == pattern {
+			// hit found!
+			hits = append(hits, i)
+		}
+	}
+
+	return hits
+}
+
+func SkewArray(genome string) []int {
+	n := len(genome)
+	array := make([]int, n+1)
+
+	for i := range genome {
+		/*
+		   array[i+1] = array[i] + something
+		   something = -1, 0, 1 depending genome[i]
+		*/
+		if genome[i] == 'A' || genome[i] == 'T' {
+			array[i+1] = array[i]
+		} else if genome[i] == 'C' {
+			array[i+1] = array[i] - 1
+		} else if genome[i] == 'G' {
+			array[i+1] = array[i] + 1
+		}
+	}
+
+	return array
+}
+
+func ReverseComplement(text string) string {
+	return Reverse(Complement(text))
+}
+
+//CanonicalKmer returns whichever of a k-mer and its reverse complement comes
+//first alphabetically. A k-mer and its reverse complement are the same piece of
+//double-stranded DNA read from opposite strands, so they share a canonical k-mer.
+func CanonicalKmer(kmer string) string {
+	rc := ReverseComplement(kmer)
+	if rc < kmer {
+		return rc
+	}
+	return kmer
+}
+
+//Reverse takes a string and returns the reversed string.
+func Reverse(text string) string {
+	n := len(text)
+	symbols := make([]byte, n)
+	for i := range text {
+		symbols[i] = text[n-i-1]
+	}
+	return string(symbols)
+}
+
+func Complement(text string) string {
+	// as with arrays, we can use "range"
+
+	n := len(text)
+	symbols := make([]byte, n)
+
+	for i := range text {
+		if text[i] == 'A' {
+			symbols[i] = 'T'
+		} else if text[i] == 'T' {
+			symbols[i] = 'A'
+		} else if text[i] == 'C' {
+			symbols[i] = 'G'
+		} else if text[i] == 'G' {
+			symbols[i] = 'C'
+		}
+	}
+
+	return string(symbols)
+}
@@ -0,0 +1,6 @@
+//Package packed stores DNA two bits per base instead of a byte. A Sequence packs
+//32 bases into every uint64, a k-mer of up to 32 bases is a single number
+//(Kmer), and longer k-mers take one word per 32 bases (LongKmer). Numbers make
+//cheap map keys: no substrings to allocate and little to hash. The iterators
+//roll over the k-mers of a string, updating the number one base at a time.
+package packed
@@ -0,0 +1,251 @@
+package packed
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	walker "github.com/kaushikvemparala/Walker"
+)
+
+// we write A, C, G, T as 0, 1, 2, 3, two bits each. A k-mer is then the number
+// whose base-4 digits are its symbols, first symbol first, so k-mers of the
+// same length sort like the strings they stand for. Moving one symbol to the
+// right along a text drops the first digit and appends a new last one:
+// kmer = (kmer<<2 | code) with the bits above 2k masked off.
+
+//MaxKmerLength is the longest k-mer that fits in a Kmer.
+const MaxKmerLength = 32
+
+//Kmer is a k-mer of at most MaxKmerLength bases packed into a number. The
+//number doesn't say what k is, so k-mers of different lengths shouldn't be
+//mixed.
+type Kmer uint64
+
+//LongKmer is a k-mer of any length packed one word per MaxKmerLength bases
+//(the last word holding what's left), written out as a string so that it can
+//be a map key.
+type LongKmer string
+
+//symbols decodes the two-bit codes.
+const symbols = "ACGT"
+
+//codes holds the two-bit code of every symbol, or -1 for symbols other than A,
+//C, G, T. A table lookup is quicker than a switch in the iterators' loops.
+var codes = func() [256]int8 {
+	var table [256]int8
+	for c := range table {
+		table[c] = -1
+	}
+	for i := 0; i < len(symbols); i++ {
+		table[symbols[i]] = int8(i)
+	}
+	return table
+}()
+
+//code returns the two-bit code of a symbol, and false if it isn't one of A, C,
+//G, T.
+func code(symbol byte) (uint64, bool) {
+	c := codes[symbol]
+	return uint64(c), c >= 0
+}
+
+//kmerMask has ones in the low 2k bits, where a k-mer lives.
+func kmerMask(k int) uint64 {
+	if k >= MaxKmerLength {
+		return ^uint64(0)
+	}
+	return 1<<(2*uint(k)) - 1
+}
+
+//EncodeKmer packs a pattern of at most MaxKmerLength symbols. It returns false
+//if the pattern is too long or has a symbol other than A, C, G, T.
+func EncodeKmer(pattern string) (Kmer, bool) {
+	if len(pattern) > MaxKmerLength {
+		return 0, false
+	}
+	var kmer uint64
+	for i := 0; i < len(pattern); i++ {
+		c, ok := code(pattern[i])
+		if !ok {
+			return 0, false
+		}
+		kmer = kmer<<2 | c
+	}
+	return Kmer(kmer), true
+}
+
+//Decode spells out a k-mer of length k.
+func (kmer Kmer) Decode(k int) string {
+	text := make([]byte, k)
+	for i := k - 1; i >= 0; i-- {
+		text[i] = symbols[kmer&3]
+		kmer >>= 2
+	}
+	return string(text)
+}
+
+//longKmer writes out the words of a long k-mer, most significant byte first.
+func longKmer(words []uint64) LongKmer {
+	key := make([]byte, 0, 8*len(words))
+	for _, word := range words {
+		key = binary.BigEndian.AppendUint64(key, word)
+	}
+	return LongKmer(key)
+}
+
+//EncodeLongKmer packs a pattern of any length. It returns false if the pattern
+//has a symbol other than A, C, G, T.
+func EncodeLongKmer(pattern string) (LongKmer, bool) {
+	words := make([]uint64, 0, (len(pattern)+MaxKmerLength-1)/MaxKmerLength)
+	for start := 0; start < len(pattern); start += MaxKmerLength {
+		word, ok := EncodeKmer(pattern[start:min(start+MaxKmerLength, len(pattern))])
+		if !ok {
+			return "", false
+		}
+		words = append(words, uint64(word))
+	}
+	return longKmer(words), true
+}
+
+//Decode spells out a long k-mer of length k.
+func (kmer LongKmer) Decode(k int) string {
+	var text strings.Builder
+	for w := 0; 8*w < len(kmer); w++ {
+		word := binary.BigEndian.Uint64([]byte(kmer[8*w : 8*w+8]))
+		text.WriteString(Kmer(word).Decode(min(MaxKmerLength, k-w*MaxKmerLength)))
+	}
+	return text.String()
+}
+
+//KmerIterator rolls over the k-mers of a text from left to right, packing each
+//one from the last in constant time. Windows with a symbol other than A, C, G,
+//T are skipped. Use it like a scanner:
+//
+//	kmers, err := packed.NewKmerIterator(text, k)
+//	...
+//	for kmers.Next() {
+//		... kmers.Kmer(), kmers.Position() ...
+//	}
+type KmerIterator struct {
+	text string
+	k    int
+	mask uint64
+	next int // the next symbol to read
+	run  int // how many symbols in a row we've read that are A, C, G or T
+	kmer uint64
+}
+
+//NewKmerIterator returns an iterator over the k-mers of text, or a
+//*walker.ParameterError unless 1 <= k <= MaxKmerLength; see LongKmerIterator
+//for longer k-mers.
+func NewKmerIterator(text string, k int) (*KmerIterator, error) {
+	if k < 1 || k > MaxKmerLength {
+		return nil, &walker.ParameterError{Name: "k", Value: k, Reason: fmt.Sprintf("must be between 1 and %d", MaxKmerLength)}
+	}
+	return &KmerIterator{text: text, k: k, mask: kmerMask(k)}, nil
+}
+
+//Next moves to the next k-mer, and returns false when there are no more.
+func (kmers *KmerIterator) Next() bool {
+	// working on local copies lets the compiler keep them in registers
+	next, run, kmer := kmers.next, kmers.run, kmers.kmer
+	for next < len(kmers.text) {
+		c := codes[kmers.text[next]]
+		next++
+		if c < 0 {
+			run = 0
+			continue
+		}
+		kmer = (kmer<<2 | uint64(c)) & kmers.mask
+		run++
+		if run >= kmers.k {
+			kmers.next, kmers.run, kmers.kmer = next, run, kmer
+			return true
+		}
+	}
+	kmers.next, kmers.run = next, run
+	return false
+}
+
+//Kmer returns the current k-mer.
+func (kmers *KmerIterator) Kmer() Kmer {
+	return Kmer(kmers.kmer)
+}
+
+//Position returns where the current k-mer starts in the text.
+func (kmers *KmerIterator) Position() int {
+	return kmers.next - kmers.k
+}
+
+//LongKmerIterator is KmerIterator for k-mers of any length. Moving to the next
+//k-mer shifts every word of the current one, so it takes time proportional to
+//k/MaxKmerLength rather than k.
+type LongKmerIterator struct {
+	text  string
+	k     int
+	masks []uint64 // masks[w] covers the bases in word w
+	tops  []uint   // tops[w] is how far to shift word w to get its first base
+	words []uint64 // the current k-mer, as LongKmer lays it out
+	next  int
+	run   int
+}
+
+//NewLongKmerIterator returns an iterator over the k-mers of text, or a
+//*walker.ParameterError if k isn't positive.
+func NewLongKmerIterator(text string, k int) (*LongKmerIterator, error) {
+	if k < 1 {
+		return nil, &walker.ParameterError{Name: "k", Value: k, Reason: "must be at least 1"}
+	}
+	numWords := (k + MaxKmerLength - 1) / MaxKmerLength
+	kmers := &LongKmerIterator{
+		text:  text,
+		k:     k,
+		masks: make([]uint64, numWords),
+		tops:  make([]uint, numWords),
+		words: make([]uint64, numWords),
+	}
+	for w := range kmers.words {
+		length := min(MaxKmerLength, k-w*MaxKmerLength)
+		kmers.masks[w] = kmerMask(length)
+		kmers.tops[w] = 2 * uint(length-1)
+	}
+	return kmers, nil
+}
+
+//Next moves to the next k-mer, and returns false when there are no more.
+func (kmers *LongKmerIterator) Next() bool {
+	words, masks, tops, last := kmers.words, kmers.masks, kmers.tops, len(kmers.words)-1
+	next, run := kmers.next, kmers.run
+	for next < len(kmers.text) {
+		c := codes[kmers.text[next]]
+		next++
+		if c < 0 {
+			run = 0
+			continue
+		}
+		// every word takes on the first base of the word after it, and the last
+		// word takes the new base
+		for w := 0; w < last; w++ {
+			words[w] = (words[w]<<2 | words[w+1]>>tops[w+1]&3) & masks[w]
+		}
+		words[last] = (words[last]<<2 | uint64(c)) & masks[last]
+		run++
+		if run >= kmers.k {
+			kmers.next, kmers.run = next, run
+			return true
+		}
+	}
+	kmers.next, kmers.run = next, run
+	return false
+}
+
+//Kmer returns the current k-mer.
+func (kmers *LongKmerIterator) Kmer() LongKmer {
+	return longKmer(kmers.words)
+}
+
+//Position returns where the current k-mer starts in the text.
+func (kmers *LongKmerIterator) Position() int {
+	return kmers.next - kmers.k
+}
@@ -0,0 +1,73 @@
+package packed
+
+import (
+	"fmt"
+
+	walker "github.com/kaushikvemparala/Walker"
+)
+
+//Sequence is a DNA sequence packed MaxKmerLength bases to a word, a quarter of
+//the memory of a string. Within a word the first base takes the top two bits,
+//and the last word is padded with zeros at the bottom.
+type Sequence struct {
+	words  []uint64
+	length int
+}
+
+//Pack packs a string of A's, C's, G's and T's. It returns a
+//*walker.ParameterError naming the first position with any other symbol.
+func Pack(text string) (*Sequence, error) {
+	sequence := &Sequence{
+		words:  make([]uint64, (len(text)+MaxKmerLength-1)/MaxKmerLength),
+		length: len(text),
+	}
+	for i := 0; i < len(text); i++ {
+		c, ok := code(text[i])
+		if !ok {
+			return nil, &walker.ParameterError{Name: fmt.Sprintf("text[%d]", i), Value: string(text[i]), Reason: "must be A, C, G or T"}
+		}
+		sequence.words[i/MaxKmerLength] |= c << (62 - 2*uint(i%MaxKmerLength))
+	}
+	return sequence, nil
+}
+
+//Len returns the number of bases in the sequence.
+func (sequence *Sequence) Len() int {
+	return sequence.length
+}
+
+//At returns the base at position i.
+func (sequence *Sequence) At(i int) byte {
+	return symbols[sequence.words[i/MaxKmerLength]>>(62-2*uint(i%MaxKmerLength))&3]
+}
+
+//String unpacks the sequence.
+func (sequence *Sequence) String() string {
+	text := make([]byte, sequence.length)
+	for i := range text {
+		text[i] = sequence.At(i)
+	}
+	return string(text)
+}
+
+//Kmer returns the k-mer starting at position i, for k <= MaxKmerLength. It
+//takes at most two words whatever k is.
+func (sequence *Sequence) Kmer(i, k int) Kmer {
+	w, offset := i/MaxKmerLength, uint(i%MaxKmerLength)
+	// slide the k-mer to the top of a word, then down to the bottom
+	bits := sequence.words[w] << (2 * offset)
+	if int(offset)+k > MaxKmerLength {
+		bits |= sequence.words[w+1] >> (2 * (MaxKmerLength - offset))
+	}
+	return Kmer(bits >> (2 * uint(MaxKmerLength-k)))
+}
+
+//LongKmer returns the k-mer of any length starting at position i, packed the
+//same way as EncodeLongKmer packs its spelling.
+func (sequence *Sequence) LongKmer(i, k int) LongKmer {
+	words := make([]uint64, 0, (k+MaxKmerLength-1)/MaxKmerLength)
+	for start := 0; start < k; start += MaxKmerLength {
+		words = append(words, uint64(sequence.Kmer(i+start, min(MaxKmerLength, k-start))))
+	}
+	return longKmer(words)
+}
@@ -0,0 +1,63 @@
+package seqio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+//magic bytes at the start of compressed files
+var (
+	gzipMagic      = []byte{0x1f, 0x8b}
+	bzip2Magic     = []byte("BZh")
+	zstdMagicBytes = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+//sequenceFile is an open input file, possibly behind a decompressor.
+//Closing it closes both.
+type sequenceFile struct {
+	io.Reader
+	decompressor io.Closer
+	file         *os.File
+}
+
+func (f *sequenceFile) Close() error {
+	if f.decompressor != nil {
+		f.decompressor.Close()
+	}
+	return f.file.Close()
+}
+
+//OpenSequenceFile opens a FASTA or FASTQ file for reading. If the file is
+//gzip, bzip2 or zstd compressed (judging by its first few bytes, not its name)
+//it is decompressed on the fly as it is read.
+func OpenSequenceFile(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := bufio.NewReaderSize(file, 1024*1024)
+	// a short file just won't match any magic number, so ignore Peek's error
+	start, _ := buffered.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(start, gzipMagic):
+		// gzip.Reader reads concatenated members too, so bgzip files work
+		decompressor, err := gzip.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &sequenceFile{Reader: decompressor, decompressor: decompressor, file: file}, nil
+	case bytes.HasPrefix(start, bzip2Magic):
+		return &sequenceFile{Reader: bzip2.NewReader(buffered), file: file}, nil
+	case bytes.HasPrefix(start, zstdMagicBytes):
+		return &sequenceFile{Reader: newZstdReader(buffered), file: file}, nil
+	default:
+		return &sequenceFile{Reader: buffered, file: file}, nil
+	}
+}
@@ -0,0 +1,6 @@
+//Package seqio reads sequencing data. It parses FASTA and FASTQ files (plain,
+//gzip, bzip2 or zstd compressed, detected from the file contents) into Read
+//records, either all at once (CollectReadsFromFASTA, CollectReadsFromFASTQ)
+//or one read at a time through a ReadStream, and writes FASTA records. Paired
+//reads, from two files or one interleaved file, are collected into ReadPairs.
+package seqio
@@ -0,0 +1,29 @@
+package seqio
+
+import (
+	"errors"
+	"fmt"
+)
+
+//ErrInvalidFormat means an input file isn't well-formed FASTA or FASTQ.
+//Every *FormatError wraps it.
+var ErrInvalidFormat = errors.New("invalid file format")
+
+//FormatError reports malformed input, with the line where we noticed it
+//(Line is 0 when it doesn't apply).
+type FormatError struct {
+	Format string // "FASTA", "FASTQ", ...
+	Line   int
+	Reason string
+}
+
+func (e *FormatError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s line %d: %s", e.Format, e.Line, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s", e.Format, e.Reason)
+}
+
+func (e *FormatError) Unwrap() error {
+	return ErrInvalidFormat
+}
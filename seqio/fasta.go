@@ -0,0 +1,152 @@
+package seqio
+
+import (
+	"io"
+	"strings"
+)
+
+//CollectReadsFromFASTA takes the name of a FASTA file and returns its reads.
+//Reads with identical sequences are collapsed into one record whose
+//Multiplicity counts how many times the sequence was seen; the ID and
+//description of the first occurrence are kept.
+//Reads containing symbols other than A, C, G, T are skipped.
+//Errors opening the file are returned as they come from the os package;
+//malformed files give a *FormatError.
+func CollectReadsFromFASTA(filename string) ([]Read, error) {
+	stream, err := OpenFASTAStream(filename)
+
+	if err != nil {
+		// error in opening file (probably you gave wrong filename)
+		return nil, err
+	}
+
+	return CollectReads(FilterReadStream(stream, 1))
+}
+
+//OpenFASTAStream opens a FASTA file (possibly compressed) and returns a stream over its reads.
+func OpenFASTAStream(filename string) (ReadStream, error) {
+	file, err := OpenSequenceFile(filename) // transparently decompresses .gz, .bz2 and .zst
+	if err != nil {
+		return nil, err
+	}
+	return newFASTAStream(file, file), nil
+}
+
+//fastaStream parses FASTA records one at a time. Since a record only ends when
+//we see the next header, it holds on to that header for the following call.
+type fastaStream struct {
+	scanner    *lineScanner // think of this as a "reader bot"
+	file       io.Closer
+	nextHeader string
+	current    Read
+	err        error
+}
+
+func newFASTAStream(r io.Reader, file io.Closer) *fastaStream {
+	return &fastaStream{scanner: newLineScanner(r), file: file}
+}
+
+func (s *fastaStream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	header := s.nextHeader
+	s.nextHeader = ""
+	var sequence strings.Builder
+
+	// go for as long as the reader bot can still see text
+	for s.scanner.Scan() {
+		currentLine := s.scanner.Text()
+		if currentLine == "" {
+			continue
+		}
+		if currentLine[0] != '>' {
+			// append the current line to our growing read
+			sequence.WriteString(currentLine)
+			continue
+		}
+		// we are at a header
+		if header == "" {
+			if sequence.Len() > 0 {
+				// the sequence so far belongs to no read
+				s.err = &FormatError{Format: "FASTA", Line: s.scanner.line, Reason: "sequence data before the first header"}
+				return false
+			}
+			// this is the first header, the read starts now
+			header = currentLine
+			continue
+		}
+		// the current read is complete! :) save the new header for next time
+		s.nextHeader = currentLine
+		break
+	}
+	if s.scanner.Err() != nil {
+		s.err = s.scanner.Err()
+		return false
+	}
+	if header == "" {
+		if sequence.Len() > 0 {
+			s.err = &FormatError{Format: "FASTA", Line: s.scanner.line, Reason: "sequence data before the first header"}
+		}
+		return false // end of file
+	}
+
+	id, description := ParseHeader(header)
+	// soft-masked (lowercase) bases are still bases, so we uppercase them as the
+	// FASTQ reader does
+	s.current = Read{
+		ID:           id,
+		Description:  description,
+		Sequence:     strings.ToUpper(sequence.String()),
+		Multiplicity: 1,
+	}
+	return true
+}
+
+func (s *fastaStream) Read() Read {
+	return s.current
+}
+
+func (s *fastaStream) Err() error {
+	return s.err
+}
+
+func (s *fastaStream) Close() error {
+	return s.file.Close()
+}
+
+func ValidDNAString(dna string) bool {
+	// check if dna is composed of A's, C's, G's, T's
+	for _, symbol := range dna {
+		if symbol != 'A' && symbol != 'C' && symbol != 'G' && symbol != 'T' {
+			return false
+		}
+	}
+	// we made it!
+	return true
+}
+
+//DefaultFASTALineWidth is the sequence line width used for FASTA output
+//unless the caller asks for something else.
+const DefaultFASTALineWidth = 80
+
+//WriteFASTA writes one FASTA record: a header line made of the ID and (if not
+//empty) the description, followed by the sequence wrapped every lineWidth
+//symbols. lineWidth <= 0 puts the whole sequence on one line.
+//Passing a *bufio.Writer is much faster when writing many records.
+func WriteFASTA(w io.Writer, id, description, sequence string, lineWidth int) error {
+	header := ">" + id
+	if description != "" {
+		header += " " + description
+	}
+	_, err := io.WriteString(w, header+"\n")
+	for err == nil && len(sequence) > 0 {
+		n := lineWidth
+		if n <= 0 || n > len(sequence) {
+			n = len(sequence)
+		}
+		_, err = io.WriteString(w, sequence[:n]+"\n")
+		sequence = sequence[n:]
+	}
+	return err
+}
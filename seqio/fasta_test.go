@@ -0,0 +1,54 @@
+package seqio
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//readFASTA parses text as a FASTA file and returns its reads and the error the
+//stream ends with.
+func readFASTA(text string) ([]Read, error) {
+	stream := newFASTAStream(strings.NewReader(text), io.NopCloser(nil))
+	reads := make([]Read, 0)
+	for stream.Next() {
+		reads = append(reads, stream.Read())
+	}
+	return reads, stream.Err()
+}
+
+func TestFASTAStream(t *testing.T) {
+	text := "\n>r1 first read\nACGT\nacgt\n\n>r2\n>r3\nTTTT\n"
+	want := []Read{
+		{ID: "r1", Description: "first read", Sequence: "ACGTACGT", Multiplicity: 1},
+		{ID: "r2", Sequence: "", Multiplicity: 1},
+		{ID: "r3", Sequence: "TTTT", Multiplicity: 1},
+	}
+	got, err := readFASTA(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestFASTASequenceBeforeHeader(t *testing.T) {
+	tests := map[string]int{
+		"GGGG\n>r1\nACGT\n":     2,
+		"\nGG\nGG\n>r1\nACGT\n": 4,
+		"GGGG\n":                1,
+	}
+	for text, line := range tests {
+		reads, err := readFASTA(text)
+		if len(reads) != 0 {
+			t.Errorf("%q: got reads %+v, want none", text, reads)
+		}
+		var formatError *FormatError
+		if !errors.As(err, &formatError) || formatError.Line != line {
+			t.Errorf("%q: got error %v, want a FormatError at line %d", text, err, line)
+		}
+	}
+}
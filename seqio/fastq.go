@@ -0,0 +1,250 @@
+package seqio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//Phred quality offsets. FASTQ files encode each quality score q as the
+//character with code q+offset. Modern (Sanger/Illumina 1.8+) files use 33,
+//old Illumina 1.3-1.7 files use 64. PhredOffsetAuto asks the reader to guess.
+const (
+	PhredOffsetAuto = 0
+	PhredOffset33   = 33
+	PhredOffset64   = 64
+)
+
+//CollectReadsFromFASTQ takes the name of a FASTQ file and a Phred offset
+//(PhredOffset33, PhredOffset64 or PhredOffsetAuto). It returns every read in
+//the file along with its ID and decoded quality scores.
+//Records may be wrapped over several lines, as in FASTA.
+//Errors opening the file are returned as they come from the os package;
+//malformed files give a *FormatError.
+func CollectReadsFromFASTQ(filename string, phredOffset int) ([]Read, error) {
+	stream, err := OpenFASTQStream(filename, phredOffset)
+
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	reads := make([]Read, 0)
+	for stream.Next() {
+		reads = append(reads, stream.Read())
+		if len(reads)%20000 == 0 {
+			fmt.Println("Update: we have processed", len(reads), "reads.")
+		}
+	}
+
+	if stream.Err() != nil {
+		return nil, stream.Err()
+	}
+
+	return reads, nil
+}
+
+//OpenFASTQStream opens a FASTQ file (possibly compressed) and returns a stream
+//over its reads, decoding qualities with the given Phred offset.
+func OpenFASTQStream(filename string, phredOffset int) (ReadStream, error) {
+	file, err := OpenSequenceFile(filename) // transparently decompresses .gz, .bz2 and .zst
+	if err != nil {
+		return nil, err
+	}
+	return newFASTQStream(file, file, phredOffset), nil
+}
+
+//phredDetectionWindow is how many records a FASTQ stream looks at to guess
+//the Phred offset before it hands out its first read.
+const phredDetectionWindow = 10000
+
+//fastqRecord is a parsed FASTQ record whose qualities are still encoded.
+type fastqRecord struct {
+	read    Read
+	quality string
+	line    int // where the record's header is
+}
+
+//fastqStream parses FASTQ records one at a time.
+type fastqStream struct {
+	scanner     *lineScanner
+	file        io.Closer
+	phredOffset int
+	// records read ahead while guessing the Phred offset
+	lookahead []fastqRecord
+	current   Read
+	err       error
+}
+
+func newFASTQStream(r io.Reader, file io.Closer, phredOffset int) *fastqStream {
+	return &fastqStream{scanner: newLineScanner(r), file: file, phredOffset: phredOffset}
+}
+
+func (s *fastqStream) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	if s.phredOffset == PhredOffsetAuto {
+		// we can't decode qualities until we know the offset, so read ahead
+		// a while and guess from what we've seen
+		qualities := make([]string, 0, phredDetectionWindow)
+		for len(s.lookahead) < phredDetectionWindow {
+			record, ok := s.parseRecord()
+			if !ok {
+				break
+			}
+			s.lookahead = append(s.lookahead, record)
+			qualities = append(qualities, record.quality)
+		}
+		if s.err != nil {
+			return false
+		}
+		s.phredOffset = DetectPhredOffset(qualities)
+	}
+
+	var record fastqRecord
+	if len(s.lookahead) > 0 {
+		record = s.lookahead[0]
+		s.lookahead = s.lookahead[1:]
+	} else {
+		var ok bool
+		record, ok = s.parseRecord()
+		if !ok {
+			return false
+		}
+	}
+
+	scores, err := DecodePhred(record.quality, s.phredOffset)
+	if err != nil {
+		s.err = &FormatError{Format: "FASTQ", Line: record.line, Reason: err.Error()}
+		return false
+	}
+	record.read.Quality = scores
+	s.current = record.read
+	return true
+}
+
+//parseRecord reads the next record from the file. It returns false at the end
+//of the file or on an error, which it stores in s.err.
+func (s *fastqStream) parseRecord() (fastqRecord, bool) {
+	header := ""
+	for header == "" {
+		if !s.scanner.Scan() {
+			s.err = s.scanner.Err()
+			return fastqRecord{}, false
+		}
+		header = s.scanner.Text() // tolerate blank lines between records
+	}
+	line := s.scanner.line
+	if header[0] != '@' {
+		s.err = &FormatError{Format: "FASTQ", Line: s.scanner.line, Reason: "record does not start with '@'"}
+		return fastqRecord{}, false
+	}
+
+	// sequence lines run until the '+' separator
+	var sequence strings.Builder
+	sawSeparator := false
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if len(line) > 0 && line[0] == '+' {
+			sawSeparator = true
+			break
+		}
+		sequence.WriteString(line)
+	}
+	if !sawSeparator {
+		s.err = &FormatError{Format: "FASTQ", Line: line, Reason: "record is missing its '+' line"}
+		return fastqRecord{}, false
+	}
+
+	// quality lines run until we have one score per symbol.
+	// we can't stop at the next '@' since '@' is a legal quality character.
+	var quality strings.Builder
+	for quality.Len() < sequence.Len() && s.scanner.Scan() {
+		quality.WriteString(s.scanner.Text())
+	}
+	if quality.Len() != sequence.Len() {
+		s.err = &FormatError{Format: "FASTQ", Line: line, Reason: "quality string length does not match sequence length"}
+		return fastqRecord{}, false
+	}
+
+	id, description := ParseHeader(header)
+	record := fastqRecord{
+		read: Read{
+			ID:           id,
+			Description:  description,
+			Sequence:     strings.ToUpper(sequence.String()),
+			Multiplicity: 1,
+		},
+		quality: quality.String(),
+		line:    line,
+	}
+	return record, true
+}
+
+func (s *fastqStream) Read() Read {
+	return s.current
+}
+
+func (s *fastqStream) Err() error {
+	return s.err
+}
+
+func (s *fastqStream) Close() error {
+	return s.file.Close()
+}
+
+//ParseHeader takes a FASTA or FASTQ header line and splits it into the read ID
+//(everything after the leading '>' or '@' up to the first whitespace)
+//and the free-text description that follows.
+func ParseHeader(header string) (string, string) {
+	line := strings.TrimSpace(header[1:])
+	split := strings.IndexAny(line, " \t")
+	if split == -1 {
+		return line, ""
+	}
+	return line[:split], strings.TrimSpace(line[split+1:])
+}
+
+//DetectPhredOffset takes a collection of raw quality strings and guesses
+//whether they were encoded with offset 33 or 64.
+//Phred+64 can't produce characters below '@', and Phred+33 rarely goes above 'J',
+//so we look at the smallest and largest characters we see.
+func DetectPhredOffset(qualities []string) int {
+	lowest := byte(255)
+	highest := byte(0)
+	for _, quality := range qualities {
+		for i := 0; i < len(quality); i++ {
+			if quality[i] < lowest {
+				lowest = quality[i]
+			}
+			if quality[i] > highest {
+				highest = quality[i]
+			}
+		}
+	}
+
+	if lowest < '@' {
+		return PhredOffset33
+	}
+	if highest > 'J' {
+		return PhredOffset64
+	}
+	// ambiguous (or empty) -- Phred+33 is by far the more common today
+	return PhredOffset33
+}
+
+//DecodePhred takes a raw quality string and an offset and returns
+//the corresponding Phred scores.
+func DecodePhred(quality string, offset int) ([]byte, error) {
+	scores := make([]byte, len(quality))
+	for i := 0; i < len(quality); i++ {
+		if int(quality[i]) < offset {
+			return nil, errors.New("quality character below the Phred offset")
+		}
+		scores[i] = quality[i] - byte(offset)
+	}
+	return scores, nil
+}
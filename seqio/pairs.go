@@ -0,0 +1,68 @@
+package seqio
+
+import "fmt"
+
+// a paired-end (or mate-pair) library sequences both ends of each DNA fragment,
+// so its reads come in twos: the two mates of a pair are a known distance
+// apart in the genome, give or take. The mates either come in two files, where
+// the i-th read of one file is the mate of the i-th read of the other, or in a
+// single interleaved file, where every read is followed by its mate.
+
+//ReadPair is the two mates of a paired read.
+type ReadPair struct {
+	First  Read
+	Second Read
+}
+
+//CollectPairs drains two streams in step, pairing the i-th read of first with
+//the i-th read of second, and closes both. Unlike CollectReads, it keeps
+//duplicate reads, since identical mates can belong to different pairs. It
+//returns a *FormatError if one stream runs out before the other.
+func CollectPairs(first, second ReadStream) ([]ReadPair, error) {
+	defer first.Close()
+	defer second.Close()
+
+	pairs := make([]ReadPair, 0)
+	for {
+		more1, more2 := first.Next(), second.Next()
+		if !more1 || !more2 {
+			if err := first.Err(); err != nil {
+				return pairs, err
+			}
+			if err := second.Err(); err != nil {
+				return pairs, err
+			}
+			if more1 != more2 {
+				return pairs, &FormatError{Format: "paired reads", Reason: fmt.Sprintf("the two files have different numbers of reads (one ends after %d)", len(pairs))}
+			}
+			return pairs, nil
+		}
+		pairs = append(pairs, ReadPair{First: first.Read(), Second: second.Read()})
+		if len(pairs)%20000 == 0 {
+			fmt.Println("Update: we have processed", len(pairs), "pairs.")
+		}
+	}
+}
+
+//CollectInterleavedPairs drains a stream whose reads alternate between first
+//and second mates, and closes it. It returns a *FormatError if the last read
+//has no mate.
+func CollectInterleavedPairs(stream ReadStream) ([]ReadPair, error) {
+	defer stream.Close()
+
+	pairs := make([]ReadPair, 0)
+	for stream.Next() {
+		first := stream.Read()
+		if !stream.Next() {
+			if err := stream.Err(); err != nil {
+				return pairs, err
+			}
+			return pairs, &FormatError{Format: "interleaved reads", Reason: fmt.Sprintf("read %s has no mate", first.ID)}
+		}
+		pairs = append(pairs, ReadPair{First: first, Second: stream.Read()})
+		if len(pairs)%20000 == 0 {
+			fmt.Println("Update: we have processed", len(pairs), "pairs.")
+		}
+	}
+	return pairs, stream.Err()
+}
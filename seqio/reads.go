@@ -0,0 +1,62 @@
+package seqio
+
+import "strconv"
+
+//Read is a single sequencing read. ID and Description come from the header line.
+//Quality holds one decoded Phred score per symbol of Sequence (so it is already
+//offset-corrected), or nil if the read came from a file without qualities.
+//Multiplicity is how many times this exact sequence was observed in the input.
+type Read struct {
+	ID           string
+	Description  string
+	Sequence     string
+	Quality      []byte
+	Multiplicity int
+}
+
+//ReadsFromStrings takes a collection of bare sequences and wraps each one
+//in a Read, numbering them by position since there are no headers to use.
+func ReadsFromStrings(sequences []string) []Read {
+	reads := make([]Read, len(sequences))
+	for i, sequence := range sequences {
+		reads[i] = Read{
+			ID:           "read_" + strconv.Itoa(i+1),
+			Sequence:     sequence,
+			Multiplicity: 1,
+		}
+	}
+	return reads
+}
+
+//ReadSequences takes a collection of reads and returns their sequences.
+func ReadSequences(reads []Read) []string {
+	sequences := make([]string, len(reads))
+	for i := range reads {
+		sequences[i] = reads[i].Sequence
+	}
+	return sequences
+}
+
+//CollapseDuplicateReads takes a collection of reads and merges reads with
+//identical sequences into one, keeping the first header (and qualities) seen
+//and adding up multiplicities. Order of first appearance is preserved.
+func CollapseDuplicateReads(reads []Read) []Read {
+	collapsed := make([]Read, 0, len(reads))
+	position := make(map[string]int) // sequence -> index in collapsed
+
+	for _, read := range reads {
+		multiplicity := read.Multiplicity
+		if multiplicity < 1 {
+			multiplicity = 1
+		}
+		i, seen := position[read.Sequence]
+		if seen {
+			collapsed[i].Multiplicity += multiplicity
+		} else {
+			read.Multiplicity = multiplicity
+			position[read.Sequence] = len(collapsed)
+			collapsed = append(collapsed, read)
+		}
+	}
+	return collapsed
+}
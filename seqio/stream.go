@@ -0,0 +1,135 @@
+package seqio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+//ReadStream hands out reads one at a time, so that a pass over a file
+//doesn't need the whole file in memory. It is used like bufio.Scanner:
+//
+//	for stream.Next() {
+//		read := stream.Read()
+//		...
+//	}
+//	if stream.Err() != nil { ... }
+//
+//Close releases the underlying file.
+type ReadStream interface {
+	Next() bool
+	Read() Read
+	Err() error
+	Close() error
+}
+
+//OpenReadStream opens a FASTA or FASTQ file (possibly compressed) and returns
+//a stream over its reads. The format is decided by the first character of the
+//file: '>' for FASTA, '@' for FASTQ. FASTQ qualities are decoded with an
+//automatically detected Phred offset.
+func OpenReadStream(filename string) (ReadStream, error) {
+	file, err := OpenSequenceFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	buffered := bufio.NewReader(file)
+	for {
+		b, err := buffered.Peek(1)
+		if err != nil {
+			// an empty file is a perfectly good (empty) FASTA file
+			return newFASTAStream(buffered, file), nil
+		}
+		switch b[0] {
+		case '\n', '\r', ' ', '\t':
+			buffered.ReadByte() // skip leading whitespace
+		case '>':
+			return newFASTAStream(buffered, file), nil
+		case '@':
+			return newFASTQStream(buffered, file, PhredOffsetAuto), nil
+		default:
+			file.Close()
+			return nil, &FormatError{Format: filename, Line: 1, Reason: "unrecognized sequence file format (expected FASTA or FASTQ)"}
+		}
+	}
+}
+
+//lineScanner is a bufio.Scanner that counts lines, so parse errors can say
+//where they happened. It copes with long reads on a single line, which are
+//much longer than bufio.Scanner's default 64KB limit.
+type lineScanner struct {
+	*bufio.Scanner
+	line int
+}
+
+func newLineScanner(r io.Reader) *lineScanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	return &lineScanner{Scanner: scanner}
+}
+
+func (s *lineScanner) Scan() bool {
+	if s.Scanner.Scan() {
+		s.line++
+		return true
+	}
+	return false
+}
+
+//filteredStream passes through only the reads a test accepts.
+type filteredStream struct {
+	ReadStream
+	keep func(Read) bool
+}
+
+func (s *filteredStream) Next() bool {
+	for s.ReadStream.Next() {
+		if s.keep(s.ReadStream.Read()) {
+			return true
+		}
+	}
+	return false
+}
+
+//FilterReadStream takes a stream and a minimum read length. It returns a stream
+//that skips reads shorter than minReadLength and reads that aren't valid DNA,
+//so they are dropped before they ever take up memory.
+func FilterReadStream(stream ReadStream, minReadLength int) ReadStream {
+	return &filteredStream{
+		ReadStream: stream,
+		keep: func(read Read) bool {
+			return len(read.Sequence) >= minReadLength && ValidDNAString(read.Sequence)
+		},
+	}
+}
+
+//CollectReads drains a stream into a slice, for algorithms that need random
+//access to the reads. Reads with identical sequences are collapsed into one
+//record as they arrive (see CollapseDuplicateReads), and the stream is closed.
+func CollectReads(stream ReadStream) ([]Read, error) {
+	defer stream.Close()
+
+	reads := make([]Read, 0)
+	// map keys share their bytes with the stored sequences, so this costs
+	// a string header per read rather than a second copy of the data.
+	position := make(map[string]int)
+	counter := 0 // for updating user
+
+	for stream.Next() {
+		read := stream.Read()
+		if read.Multiplicity < 1 {
+			read.Multiplicity = 1
+		}
+		i, seen := position[read.Sequence]
+		if seen {
+			reads[i].Multiplicity += read.Multiplicity
+		} else {
+			position[read.Sequence] = len(reads)
+			reads = append(reads, read)
+		}
+		counter++
+		if counter%20000 == 0 {
+			fmt.Println("Update: we have processed", counter, "reads.")
+		}
+	}
+	return reads, stream.Err()
+}
@@ -0,0 +1,1065 @@
+package seqio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+)
+
+// This file is a small, self-contained Zstandard decoder (RFC 8878).
+// It handles everything the reference compressor produces for ordinary
+// files: raw, RLE and compressed blocks, Huffman-coded literals, FSE-coded
+// sequences, repeat offsets and content checksums. It does not support
+// dictionaries, which nobody uses for sequence archives anyway.
+
+const (
+	zstdMagic          = 0xFD2FB528
+	zstdSkippableMask  = 0xFFFFFFF0
+	zstdSkippableMagic = 0x184D2A50
+	zstdMaxBlockSize   = 128 * 1024
+	zstdMaxWindowSize  = 1 << 31
+)
+
+var errZstdCorrupt = errors.New("zstd: corrupt input")
+
+//zstdReader decompresses a zstd stream one block at a time, so that at most
+//one window of history is held in memory.
+type zstdReader struct {
+	in  io.Reader
+	err error
+
+	// output waiting to be handed to the caller
+	pending []byte
+
+	// per-frame state
+	inFrame     bool
+	lastBlock   bool
+	windowSize  int
+	hasChecksum bool
+	checksum    *xxhash64
+	history     []byte
+	repeats     [3]int
+	huffman     *huffmanTable
+	llTable     *fseTable
+	ofTable     *fseTable
+	mlTable     *fseTable
+	blockBuffer []byte
+}
+
+//newZstdReader takes a reader over zstd-compressed data and returns a reader
+//over the decompressed data. Concatenated and skippable frames are supported.
+func newZstdReader(in io.Reader) *zstdReader {
+	return &zstdReader{in: in}
+}
+
+func (z *zstdReader) Read(p []byte) (int, error) {
+	for len(z.pending) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.err = z.advance()
+	}
+	n := copy(p, z.pending)
+	z.pending = z.pending[n:]
+	return n, nil
+}
+
+func (z *zstdReader) Close() error {
+	if closer, ok := z.in.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+//advance decodes the next block (starting a new frame if necessary)
+//and leaves its output in z.pending.
+func (z *zstdReader) advance() error {
+	if !z.inFrame {
+		started, err := z.startFrame()
+		if err != nil {
+			return err
+		}
+		if !started {
+			return nil // skippable frame, go around again
+		}
+	}
+
+	var header [3]byte
+	if _, err := io.ReadFull(z.in, header[:]); err != nil {
+		return unexpectedEOF(err)
+	}
+	blockHeader := uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16
+	z.lastBlock = blockHeader&1 == 1
+	blockType := (blockHeader >> 1) & 3
+	blockSize := int(blockHeader >> 3)
+
+	start := len(z.history)
+	switch blockType {
+	case 0: // raw
+		if blockSize > zstdMaxBlockSize {
+			return errZstdCorrupt
+		}
+		z.history = append(z.history, make([]byte, blockSize)...)
+		if _, err := io.ReadFull(z.in, z.history[start:]); err != nil {
+			return unexpectedEOF(err)
+		}
+	case 1: // RLE: a single byte repeated blockSize times
+		if blockSize > zstdMaxBlockSize {
+			return errZstdCorrupt
+		}
+		var b [1]byte
+		if _, err := io.ReadFull(z.in, b[:]); err != nil {
+			return unexpectedEOF(err)
+		}
+		for i := 0; i < blockSize; i++ {
+			z.history = append(z.history, b[0])
+		}
+	case 2: // compressed
+		if blockSize > zstdMaxBlockSize {
+			return errZstdCorrupt
+		}
+		if cap(z.blockBuffer) < blockSize {
+			z.blockBuffer = make([]byte, blockSize)
+		}
+		block := z.blockBuffer[:blockSize]
+		if _, err := io.ReadFull(z.in, block); err != nil {
+			return unexpectedEOF(err)
+		}
+		if err := z.decompressBlock(block); err != nil {
+			return err
+		}
+	default:
+		return errZstdCorrupt
+	}
+
+	output := z.history[start:]
+	if z.hasChecksum {
+		z.checksum.Write(output)
+	}
+	z.pending = append(z.pending[:0], output...)
+
+	// only the last window of output can be referred to by later blocks
+	if len(z.history) > 2*z.windowSize+zstdMaxBlockSize {
+		keep := z.history[len(z.history)-z.windowSize:]
+		z.history = append(z.history[:0], keep...)
+	}
+
+	if z.lastBlock {
+		z.inFrame = false
+		if z.hasChecksum {
+			var stored [4]byte
+			if _, err := io.ReadFull(z.in, stored[:]); err != nil {
+				return unexpectedEOF(err)
+			}
+			if binary.LittleEndian.Uint32(stored[:]) != uint32(z.checksum.Sum64()) {
+				return errors.New("zstd: checksum mismatch")
+			}
+		}
+	}
+	return nil
+}
+
+//startFrame reads a frame header. It returns false if the frame was a
+//skippable frame (which it consumes), and io.EOF at the clean end of input.
+func (z *zstdReader) startFrame() (bool, error) {
+	var magic [4]byte
+	n, err := io.ReadFull(z.in, magic[:])
+	if n == 0 && err == io.EOF {
+		return false, io.EOF
+	}
+	if err != nil {
+		return false, unexpectedEOF(err)
+	}
+	m := binary.LittleEndian.Uint32(magic[:])
+	if m&zstdSkippableMask == zstdSkippableMagic {
+		var size [4]byte
+		if _, err := io.ReadFull(z.in, size[:]); err != nil {
+			return false, unexpectedEOF(err)
+		}
+		_, err := io.CopyN(io.Discard, z.in, int64(binary.LittleEndian.Uint32(size[:])))
+		return false, unexpectedEOF(err)
+	}
+	if m != zstdMagic {
+		return false, errors.New("zstd: bad frame magic number")
+	}
+
+	var descriptor [1]byte
+	if _, err := io.ReadFull(z.in, descriptor[:]); err != nil {
+		return false, unexpectedEOF(err)
+	}
+	fcsFlag := descriptor[0] >> 6
+	singleSegment := descriptor[0]&0x20 != 0
+	if descriptor[0]&0x08 != 0 {
+		return false, errZstdCorrupt // reserved bit
+	}
+	z.hasChecksum = descriptor[0]&0x04 != 0
+	dictionaryBytes := [4]int{0, 1, 2, 4}[descriptor[0]&3]
+
+	fcsBytes := [4]int{0, 2, 4, 8}[fcsFlag]
+	if fcsFlag == 0 && singleSegment {
+		fcsBytes = 1
+	}
+	windowBytes := 1
+	if singleSegment {
+		windowBytes = 0
+	}
+
+	header := make([]byte, windowBytes+dictionaryBytes+fcsBytes)
+	if _, err := io.ReadFull(z.in, header); err != nil {
+		return false, unexpectedEOF(err)
+	}
+
+	windowSize := uint64(0)
+	if !singleSegment {
+		exponent := uint(header[0] >> 3)
+		mantissa := uint64(header[0] & 7)
+		base := uint64(1) << (10 + exponent)
+		windowSize = base + (base/8)*mantissa
+	}
+	dictionary := header[windowBytes : windowBytes+dictionaryBytes]
+	for _, b := range dictionary {
+		if b != 0 {
+			return false, errors.New("zstd: dictionaries are not supported")
+		}
+	}
+	fcs := header[windowBytes+dictionaryBytes:]
+	if singleSegment {
+		var contentSize uint64
+		for i := len(fcs) - 1; i >= 0; i-- {
+			contentSize = contentSize<<8 | uint64(fcs[i])
+		}
+		if len(fcs) == 2 {
+			contentSize += 256
+		}
+		windowSize = contentSize
+	}
+	if windowSize > zstdMaxWindowSize {
+		return false, errors.New("zstd: window too large")
+	}
+
+	z.inFrame = true
+	z.lastBlock = false
+	z.windowSize = int(windowSize)
+	if z.windowSize < zstdMaxBlockSize {
+		z.windowSize = zstdMaxBlockSize
+	}
+	z.history = z.history[:0]
+	z.repeats = [3]int{1, 4, 8}
+	z.huffman = nil
+	z.llTable, z.ofTable, z.mlTable = nil, nil, nil
+	if z.hasChecksum {
+		z.checksum = newXXHash64()
+	}
+	return true, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+//decompressBlock decodes one compressed block, appending its output to z.history.
+func (z *zstdReader) decompressBlock(block []byte) error {
+	literals, consumed, err := z.decodeLiterals(block)
+	if err != nil {
+		return err
+	}
+	return z.decodeSequences(block[consumed:], literals)
+}
+
+//decodeLiterals decodes the literals section at the start of a compressed block.
+//It returns the literals and the number of bytes of block it used.
+func (z *zstdReader) decodeLiterals(block []byte) ([]byte, int, error) {
+	if len(block) == 0 {
+		return nil, 0, errZstdCorrupt
+	}
+	literalsType := block[0] & 3
+	sizeFormat := (block[0] >> 2) & 3
+
+	if literalsType < 2 {
+		// raw or RLE literals
+		var regenerated, headerSize int
+		switch sizeFormat {
+		case 0, 2:
+			regenerated, headerSize = int(block[0]>>3), 1
+		case 1:
+			if len(block) < 2 {
+				return nil, 0, errZstdCorrupt
+			}
+			regenerated, headerSize = int(block[0]>>4)+int(block[1])<<4, 2
+		case 3:
+			if len(block) < 3 {
+				return nil, 0, errZstdCorrupt
+			}
+			regenerated, headerSize = int(block[0]>>4)+int(block[1])<<4+int(block[2])<<12, 3
+		}
+		if regenerated > zstdMaxBlockSize {
+			return nil, 0, errZstdCorrupt
+		}
+		if literalsType == 0 {
+			if len(block) < headerSize+regenerated {
+				return nil, 0, errZstdCorrupt
+			}
+			return block[headerSize : headerSize+regenerated], headerSize + regenerated, nil
+		}
+		if len(block) < headerSize+1 {
+			return nil, 0, errZstdCorrupt
+		}
+		literals := make([]byte, regenerated)
+		for i := range literals {
+			literals[i] = block[headerSize]
+		}
+		return literals, headerSize + 1, nil
+	}
+
+	// Huffman-compressed literals (type 3 reuses the previous table)
+	var headerSize, fieldBits int
+	streams := 4
+	switch sizeFormat {
+	case 0:
+		headerSize, fieldBits, streams = 3, 10, 1
+	case 1:
+		headerSize, fieldBits = 3, 10
+	case 2:
+		headerSize, fieldBits = 4, 14
+	case 3:
+		headerSize, fieldBits = 5, 18
+	}
+	if len(block) < headerSize {
+		return nil, 0, errZstdCorrupt
+	}
+	var header uint64
+	for i := headerSize - 1; i >= 0; i-- {
+		header = header<<8 | uint64(block[i])
+	}
+	mask := uint64(1)<<uint(fieldBits) - 1
+	regenerated := int((header >> 4) & mask)
+	compressed := int((header >> (4 + uint(fieldBits))) & mask)
+	if regenerated > zstdMaxBlockSize || len(block) < headerSize+compressed {
+		return nil, 0, errZstdCorrupt
+	}
+	data := block[headerSize : headerSize+compressed]
+
+	if literalsType == 2 {
+		table, used, err := readHuffmanTable(data)
+		if err != nil {
+			return nil, 0, err
+		}
+		z.huffman = table
+		data = data[used:]
+	} else if z.huffman == nil {
+		return nil, 0, errZstdCorrupt
+	}
+
+	literals := make([]byte, regenerated)
+	if streams == 1 {
+		if err := z.huffman.decodeStream(data, literals); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		if len(data) < 6 {
+			return nil, 0, errZstdCorrupt
+		}
+		sizes := [4]int{
+			int(binary.LittleEndian.Uint16(data[0:])),
+			int(binary.LittleEndian.Uint16(data[2:])),
+			int(binary.LittleEndian.Uint16(data[4:])),
+		}
+		sizes[3] = len(data) - 6 - sizes[0] - sizes[1] - sizes[2]
+		if sizes[3] < 0 {
+			return nil, 0, errZstdCorrupt
+		}
+		segment := (regenerated + 3) / 4
+		position := 6
+		for i := 0; i < 4; i++ {
+			start := i * segment
+			end := start + segment
+			if i == 3 || end > regenerated {
+				end = regenerated
+			}
+			if start > end {
+				start = end
+			}
+			if err := z.huffman.decodeStream(data[position:position+sizes[i]], literals[start:end]); err != nil {
+				return nil, 0, err
+			}
+			position += sizes[i]
+		}
+	}
+	return literals, headerSize + compressed, nil
+}
+
+//Literal length and match length codes map to a baseline plus a number of
+//extra bits read from the sequence bitstream (RFC 8878 section 3.1.1.3.2.1.1).
+var (
+	literalLengthBase = [36]int{
+		0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+		16, 18, 20, 22, 24, 28, 32, 40, 48, 64, 128, 256, 512, 1024, 2048, 4096,
+		8192, 16384, 32768, 65536,
+	}
+	literalLengthBits = [36]uint{
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		1, 1, 1, 1, 2, 2, 3, 3, 4, 6, 7, 8, 9, 10, 11, 12,
+		13, 14, 15, 16,
+	}
+	matchLengthBase = [53]int{
+		3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18,
+		19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34,
+		35, 37, 39, 41, 43, 47, 51, 59, 67, 83, 99, 131, 259, 515, 1027, 2051,
+		4099, 8195, 16387, 32771, 65539,
+	}
+	matchLengthBits = [53]uint{
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		1, 1, 1, 1, 2, 2, 3, 3, 4, 4, 5, 7, 8, 9, 10, 11,
+		12, 13, 14, 15, 16,
+	}
+
+	// predefined distributions used when a block doesn't ship its own tables
+	literalLengthDefault = []int{
+		4, 3, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 1, 1, 1,
+		2, 2, 2, 2, 2, 2, 2, 2, 2, 3, 2, 1, 1, 1, 1, 1,
+		-1, -1, -1, -1,
+	}
+	matchLengthDefault = []int{
+		1, 4, 3, 2, 2, 2, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1, -1,
+		-1, -1, -1, -1, -1,
+	}
+	offsetDefault = []int{
+		1, 1, 1, 1, 1, 1, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, -1, -1, -1, -1, -1,
+	}
+)
+
+//decodeSequences decodes the sequences section of a compressed block and
+//executes the sequences against the literals, appending output to z.history.
+func (z *zstdReader) decodeSequences(data []byte, literals []byte) error {
+	if len(data) == 0 {
+		return errZstdCorrupt
+	}
+	count := int(data[0])
+	position := 1
+	if count == 0 {
+		// no sequences: the block is just its literals
+		z.history = append(z.history, literals...)
+		return nil
+	} else if count == 255 {
+		if len(data) < 3 {
+			return errZstdCorrupt
+		}
+		count = int(data[1]) + int(data[2])<<8 + 0x7F00
+		position = 3
+	} else if count >= 128 {
+		if len(data) < 2 {
+			return errZstdCorrupt
+		}
+		count = (count-128)<<8 + int(data[1])
+		position = 2
+	}
+
+	if len(data) <= position {
+		return errZstdCorrupt
+	}
+	modes := data[position]
+	position++
+	if modes&3 != 0 {
+		return errZstdCorrupt
+	}
+
+	var err error
+	var used int
+	z.llTable, used, err = selectFSETable(modes>>6, data[position:], z.llTable, literalLengthDefault, 6, 9, 35)
+	if err != nil {
+		return err
+	}
+	position += used
+	z.ofTable, used, err = selectFSETable((modes>>4)&3, data[position:], z.ofTable, offsetDefault, 5, 8, 31)
+	if err != nil {
+		return err
+	}
+	position += used
+	z.mlTable, used, err = selectFSETable((modes>>2)&3, data[position:], z.mlTable, matchLengthDefault, 6, 9, 52)
+	if err != nil {
+		return err
+	}
+	position += used
+
+	stream, err := newReverseBitReader(data[position:])
+	if err != nil {
+		return err
+	}
+	llState := int(stream.readBits(z.llTable.accuracyLog))
+	ofState := int(stream.readBits(z.ofTable.accuracyLog))
+	mlState := int(stream.readBits(z.mlTable.accuracyLog))
+
+	literalPosition := 0
+	for i := 0; i < count; i++ {
+		ofCode := z.ofTable.entries[ofState].symbol
+		mlCode := z.mlTable.entries[mlState].symbol
+		llCode := z.llTable.entries[llState].symbol
+		if ofCode > 31 || int(mlCode) >= len(matchLengthBase) || int(llCode) >= len(literalLengthBase) {
+			return errZstdCorrupt
+		}
+
+		offsetValue := 1<<uint(ofCode) + int(stream.readBits(uint(ofCode)))
+		matchLength := matchLengthBase[mlCode] + int(stream.readBits(matchLengthBits[mlCode]))
+		literalLength := literalLengthBase[llCode] + int(stream.readBits(literalLengthBits[llCode]))
+
+		// resolve repeat offsets
+		var offset int
+		if offsetValue > 3 {
+			offset = offsetValue - 3
+			z.repeats[2], z.repeats[1], z.repeats[0] = z.repeats[1], z.repeats[0], offset
+		} else {
+			index := offsetValue - 1
+			if literalLength == 0 {
+				index++
+			}
+			if index == 0 {
+				offset = z.repeats[0]
+			} else {
+				if index == 3 {
+					offset = z.repeats[0] - 1
+				} else {
+					offset = z.repeats[index]
+				}
+				if index > 1 {
+					z.repeats[2] = z.repeats[1]
+				}
+				z.repeats[1] = z.repeats[0]
+				z.repeats[0] = offset
+			}
+		}
+
+		// execute: copy literals, then the match
+		if literalPosition+literalLength > len(literals) {
+			return errZstdCorrupt
+		}
+		z.history = append(z.history, literals[literalPosition:literalPosition+literalLength]...)
+		literalPosition += literalLength
+		if offset <= 0 || offset > len(z.history) {
+			return errZstdCorrupt
+		}
+		from := len(z.history) - offset
+		for j := 0; j < matchLength; j++ {
+			z.history = append(z.history, z.history[from+j])
+		}
+
+		if i < count-1 {
+			llState = z.llTable.nextState(llState, stream)
+			mlState = z.mlTable.nextState(mlState, stream)
+			ofState = z.ofTable.nextState(ofState, stream)
+		}
+	}
+	if !stream.finished() {
+		return errZstdCorrupt
+	}
+	z.history = append(z.history, literals[literalPosition:]...)
+	return nil
+}
+
+//selectFSETable builds (or reuses) the decoding table for one of the three
+//sequence symbol types according to its compression mode.
+func selectFSETable(mode byte, data []byte, previous *fseTable, defaults []int, defaultLog, maxLog uint, maxSymbol int) (*fseTable, int, error) {
+	switch mode {
+	case 0: // predefined
+		table, err := buildFSETable(defaults, defaultLog)
+		return table, 0, err
+	case 1: // RLE: every state decodes to the same symbol
+		if len(data) < 1 || int(data[0]) > maxSymbol {
+			return nil, 0, errZstdCorrupt
+		}
+		table := &fseTable{accuracyLog: 0, entries: []fseEntry{{symbol: data[0]}}}
+		return table, 1, nil
+	case 2: // FSE table description
+		counts, accuracyLog, used, err := readFSEDistribution(data, maxSymbol)
+		if err != nil {
+			return nil, 0, err
+		}
+		if accuracyLog > maxLog {
+			return nil, 0, errZstdCorrupt
+		}
+		table, err := buildFSETable(counts, accuracyLog)
+		return table, used, err
+	default: // repeat the previous table
+		if previous == nil {
+			return nil, 0, errZstdCorrupt
+		}
+		return previous, 0, nil
+	}
+}
+
+// ---- FSE (finite state entropy) tables ----
+
+type fseEntry struct {
+	symbol   byte
+	numBits  uint
+	baseline int
+}
+
+type fseTable struct {
+	accuracyLog uint
+	entries     []fseEntry
+}
+
+func (t *fseTable) nextState(state int, stream *reverseBitReader) int {
+	entry := t.entries[state]
+	return entry.baseline + int(stream.readBits(entry.numBits))
+}
+
+//readFSEDistribution reads a normalized symbol distribution from the start of data.
+//It returns the counts (-1 meaning "less than one"), the accuracy log and the
+//number of bytes consumed.
+func readFSEDistribution(data []byte, maxSymbol int) ([]int, uint, int, error) {
+	stream := forwardBitReader{data: data}
+	accuracyLog := uint(stream.readBits(4)) + 5
+	if accuracyLog > 15 {
+		return nil, 0, 0, errZstdCorrupt
+	}
+	counts := make([]int, 0, maxSymbol+1)
+	remaining := (1 << accuracyLog) + 1
+	threshold := 1 << accuracyLog
+	numBits := accuracyLog + 1
+
+	for remaining > 1 && len(counts) <= maxSymbol {
+		max := (2*threshold - 1) - remaining
+		var value int
+		low := int(stream.peekBits(numBits - 1))
+		if low < max {
+			value = low
+			stream.skipBits(numBits - 1)
+		} else {
+			value = int(stream.peekBits(numBits))
+			if value >= threshold {
+				value -= max
+			}
+			stream.skipBits(numBits)
+		}
+		count := value - 1
+		if count < 0 {
+			remaining -= -count
+		} else {
+			remaining -= count
+		}
+		counts = append(counts, count)
+
+		if count == 0 {
+			// a run of zero-probability symbols, coded 2 bits at a time
+			for {
+				repeat := int(stream.readBits(2))
+				for j := 0; j < repeat; j++ {
+					counts = append(counts, 0)
+				}
+				if repeat != 3 {
+					break
+				}
+			}
+		}
+		for remaining < threshold && threshold > 1 {
+			numBits--
+			threshold >>= 1
+		}
+	}
+	if remaining != 1 || len(counts) > maxSymbol+1 || stream.position > 8*len(data) {
+		return nil, 0, 0, errZstdCorrupt
+	}
+	return counts, accuracyLog, (stream.position + 7) / 8, nil
+}
+
+//buildFSETable spreads the symbols of a normalized distribution over a
+//decoding table of size 2^accuracyLog.
+func buildFSETable(counts []int, accuracyLog uint) (*fseTable, error) {
+	size := 1 << accuracyLog
+	entries := make([]fseEntry, size)
+	next := make([]int, len(counts))
+
+	// "less than one" symbols go at the very end of the table
+	high := size - 1
+	for symbol, count := range counts {
+		if count == -1 {
+			if high < 0 {
+				return nil, errZstdCorrupt
+			}
+			entries[high].symbol = byte(symbol)
+			high--
+			next[symbol] = 1
+		} else {
+			next[symbol] = count
+		}
+	}
+
+	step := (size >> 1) + (size >> 3) + 3
+	mask := size - 1
+	position := 0
+	for symbol, count := range counts {
+		for i := 0; i < count; i++ {
+			entries[position].symbol = byte(symbol)
+			position = (position + step) & mask
+			for position > high {
+				position = (position + step) & mask
+			}
+		}
+	}
+	if position != 0 {
+		return nil, errZstdCorrupt
+	}
+
+	for state := range entries {
+		symbol := entries[state].symbol
+		x := next[symbol]
+		next[symbol]++
+		if x == 0 {
+			return nil, errZstdCorrupt
+		}
+		numBits := accuracyLog - uint(bits.Len(uint(x))-1)
+		entries[state].numBits = numBits
+		entries[state].baseline = (x << numBits) - size
+	}
+	return &fseTable{accuracyLog: accuracyLog, entries: entries}, nil
+}
+
+// ---- Huffman tables for literals ----
+
+type huffmanEntry struct {
+	symbol  byte
+	numBits uint
+}
+
+type huffmanTable struct {
+	maxBits uint
+	entries []huffmanEntry
+}
+
+//readHuffmanTable reads a Huffman tree description from the start of data.
+//It returns the decoding table and the number of bytes consumed.
+func readHuffmanTable(data []byte) (*huffmanTable, int, error) {
+	if len(data) == 0 {
+		return nil, 0, errZstdCorrupt
+	}
+	header := int(data[0])
+	weights := make([]byte, 0, 256)
+	used := 0
+
+	if header >= 128 {
+		// weights stored directly, two per byte
+		numWeights := header - 127
+		used = 1 + (numWeights+1)/2
+		if len(data) < used {
+			return nil, 0, errZstdCorrupt
+		}
+		for i := 0; i < numWeights; i++ {
+			b := data[1+i/2]
+			if i%2 == 0 {
+				weights = append(weights, b>>4)
+			} else {
+				weights = append(weights, b&15)
+			}
+		}
+	} else {
+		// weights compressed with FSE, using two interleaved states
+		used = 1 + header
+		if len(data) < used {
+			return nil, 0, errZstdCorrupt
+		}
+		compressed := data[1:used]
+		counts, accuracyLog, tableBytes, err := readFSEDistribution(compressed, 255)
+		if err != nil {
+			return nil, 0, err
+		}
+		if accuracyLog > 6 {
+			return nil, 0, errZstdCorrupt
+		}
+		table, err := buildFSETable(counts, accuracyLog)
+		if err != nil {
+			return nil, 0, err
+		}
+		stream, err := newReverseBitReader(compressed[tableBytes:])
+		if err != nil {
+			return nil, 0, err
+		}
+		state1 := int(stream.readBits(accuracyLog))
+		state2 := int(stream.readBits(accuracyLog))
+		for len(weights) < 255 {
+			weights = append(weights, table.entries[state1].symbol)
+			state1 = table.nextState(state1, stream)
+			if stream.overflowed() {
+				weights = append(weights, table.entries[state2].symbol)
+				break
+			}
+			weights = append(weights, table.entries[state2].symbol)
+			state2 = table.nextState(state2, stream)
+			if stream.overflowed() {
+				weights = append(weights, table.entries[state1].symbol)
+				break
+			}
+		}
+	}
+
+	// the last symbol's weight is implied by the others summing to a power of two
+	total := 0
+	for _, w := range weights {
+		if w > 12 {
+			return nil, 0, errZstdCorrupt
+		}
+		if w > 0 {
+			total += 1 << (w - 1)
+		}
+	}
+	if total == 0 {
+		return nil, 0, errZstdCorrupt
+	}
+	maxBits := uint(bits.Len(uint(total)))
+	leftover := (1 << maxBits) - total
+	if leftover&(leftover-1) != 0 {
+		return nil, 0, errZstdCorrupt
+	}
+	weights = append(weights, byte(bits.Len(uint(leftover))))
+	if maxBits > 11 {
+		return nil, 0, errZstdCorrupt
+	}
+
+	// lower weights (longer codes) come first in the table, and within a weight
+	// symbols are in increasing order
+	entries := make([]huffmanEntry, 1<<maxBits)
+	position := 0
+	for w := byte(1); w <= byte(maxBits); w++ {
+		for symbol, weight := range weights {
+			if weight != w {
+				continue
+			}
+			length := 1 << (w - 1)
+			for i := 0; i < length; i++ {
+				entries[position+i] = huffmanEntry{symbol: byte(symbol), numBits: maxBits + 1 - uint(w)}
+			}
+			position += length
+		}
+	}
+	if position != len(entries) {
+		return nil, 0, errZstdCorrupt
+	}
+	return &huffmanTable{maxBits: maxBits, entries: entries}, used, nil
+}
+
+//decodeStream decodes exactly len(out) symbols from a single Huffman bitstream.
+func (t *huffmanTable) decodeStream(data []byte, out []byte) error {
+	stream, err := newReverseBitReader(data)
+	if err != nil {
+		return err
+	}
+	for i := range out {
+		entry := t.entries[stream.peekBits(t.maxBits)]
+		out[i] = entry.symbol
+		stream.skipBits(entry.numBits)
+	}
+	if !stream.finished() {
+		return errZstdCorrupt
+	}
+	return nil
+}
+
+// ---- bit readers ----
+
+//forwardBitReader reads a little-endian bitstream from the front, as used by
+//FSE table descriptions. Reads past the end return zeros.
+type forwardBitReader struct {
+	data     []byte
+	position int // in bits
+}
+
+func (r *forwardBitReader) peekBits(n uint) uint64 {
+	var value uint64
+	for i := uint(0); i < n; {
+		bit := r.position + int(i)
+		b := bit / 8
+		if b >= len(r.data) {
+			break
+		}
+		take := 8 - uint(bit%8)
+		if take > n-i {
+			take = n - i
+		}
+		chunk := uint64(r.data[b]>>uint(bit%8)) & (1<<take - 1)
+		value |= chunk << i
+		i += take
+	}
+	return value
+}
+
+func (r *forwardBitReader) skipBits(n uint) {
+	r.position += int(n)
+}
+
+func (r *forwardBitReader) readBits(n uint) uint64 {
+	value := r.peekBits(n)
+	r.skipBits(n)
+	return value
+}
+
+//reverseBitReader reads a bitstream backwards from its end, as used by
+//Huffman and FSE streams. The highest set bit of the last byte marks where
+//the data starts. Reads past the beginning return zeros.
+type reverseBitReader struct {
+	data     []byte
+	position int // number of unread bits; negative once we've read too far
+}
+
+func newReverseBitReader(data []byte) (*reverseBitReader, error) {
+	if len(data) == 0 || data[len(data)-1] == 0 {
+		return nil, errZstdCorrupt
+	}
+	last := data[len(data)-1]
+	return &reverseBitReader{
+		data:     data,
+		position: 8*(len(data)-1) + bits.Len8(last) - 1,
+	}, nil
+}
+
+//peekBits returns the next n bits (n <= 56) without consuming them.
+func (r *reverseBitReader) peekBits(n uint) uint64 {
+	if n == 0 {
+		return 0
+	}
+	high := r.position
+	low := high - int(n)
+	if high <= 0 {
+		return 0
+	}
+	start := low
+	if start < 0 {
+		start = 0
+	}
+	first := start / 8
+	last := (high - 1) / 8
+	var value uint64
+	for i := last; i >= first; i-- {
+		value = value<<8 | uint64(r.data[i])
+	}
+	value >>= uint(start - 8*first)
+	value &= 1<<uint(high-start) - 1
+	if low < 0 {
+		value <<= uint(-low)
+	}
+	return value
+}
+
+func (r *reverseBitReader) skipBits(n uint) {
+	r.position -= int(n)
+}
+
+func (r *reverseBitReader) readBits(n uint) uint64 {
+	value := r.peekBits(n)
+	r.skipBits(n)
+	return value
+}
+
+func (r *reverseBitReader) overflowed() bool {
+	return r.position < 0
+}
+
+func (r *reverseBitReader) finished() bool {
+	return r.position == 0
+}
+
+// ---- xxHash64, for frame checksums ----
+
+const (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+//xxhash64 is a streaming xxHash64 digest with seed 0.
+type xxhash64 struct {
+	v      [4]uint64
+	total  uint64
+	buffer [32]byte
+	filled int
+}
+
+func newXXHash64() *xxhash64 {
+	// the initial accumulators wrap around, so compute them at run time
+	var seed uint64
+	h := &xxhash64{}
+	h.v = [4]uint64{seed + xxPrime1 + xxPrime2, seed + xxPrime2, seed, seed - xxPrime1}
+	return h
+}
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+func xxMergeRound(acc, value uint64) uint64 {
+	acc ^= xxRound(0, value)
+	return acc*xxPrime1 + xxPrime4
+}
+
+func (h *xxhash64) Write(p []byte) {
+	h.total += uint64(len(p))
+	if h.filled > 0 {
+		n := copy(h.buffer[h.filled:], p)
+		h.filled += n
+		p = p[n:]
+		if h.filled < 32 {
+			return
+		}
+		h.stripe(h.buffer[:])
+		h.filled = 0
+	}
+	for len(p) >= 32 {
+		h.stripe(p[:32])
+		p = p[32:]
+	}
+	h.filled = copy(h.buffer[:], p)
+}
+
+func (h *xxhash64) stripe(p []byte) {
+	for i := 0; i < 4; i++ {
+		h.v[i] = xxRound(h.v[i], binary.LittleEndian.Uint64(p[8*i:]))
+	}
+}
+
+func (h *xxhash64) Sum64() uint64 {
+	var acc uint64
+	if h.total >= 32 {
+		acc = bits.RotateLeft64(h.v[0], 1) + bits.RotateLeft64(h.v[1], 7) +
+			bits.RotateLeft64(h.v[2], 12) + bits.RotateLeft64(h.v[3], 18)
+		for i := 0; i < 4; i++ {
+			acc = xxMergeRound(acc, h.v[i])
+		}
+	} else {
+		acc = h.v[2] + xxPrime5
+	}
+	acc += h.total
+
+	p := h.buffer[:h.filled]
+	for len(p) >= 8 {
+		acc ^= xxRound(0, binary.LittleEndian.Uint64(p))
+		acc = bits.RotateLeft64(acc, 27)*xxPrime1 + xxPrime4
+		p = p[8:]
+	}
+	if len(p) >= 4 {
+		acc ^= uint64(binary.LittleEndian.Uint32(p)) * xxPrime1
+		acc = bits.RotateLeft64(acc, 23)*xxPrime2 + xxPrime3
+		p = p[4:]
+	}
+	for _, b := range p {
+		acc ^= uint64(b) * xxPrime5
+		acc = bits.RotateLeft64(acc, 11) * xxPrime1
+	}
+
+	acc ^= acc >> 33
+	acc *= xxPrime2
+	acc ^= acc >> 29
+	acc *= xxPrime3
+	acc ^= acc >> 32
+	return acc
+}
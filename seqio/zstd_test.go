@@ -0,0 +1,255 @@
+package seqio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// The fixtures in testdata were made by the reference zstd CLI (v1.5.6):
+//
+//	zstd -3 reads.fasta -o reads.fasta.zst
+//	zstd -19 --long=27 reads.fasta -o reads_19.fasta.zst
+//	zstd --fast=3 --no-check --no-content-size reads.fasta -o reads_fast.fasta.zst
+//	zstd -3 small.fasta -o small.fasta.zst
+//	zstd -3 runs.txt -o runs.txt.zst
+//
+// reads.fasta holds 200 reads sampled, with errors, from both strands of a
+// random genome. It is bigger than one block (128 KiB), so its fixtures have
+// several compressed blocks; between them they cover Huffman and FSE tables
+// that are sent and repeated, repeat offsets, frames with and without a window
+// descriptor, and content checksums. small.fasta (the first 400 bytes of
+// reads.fasta) is small enough for predefined FSE tables and single-stream
+// Huffman literals, and runs.txt (runs of one base) gives raw and RLE literals.
+// Raw, RLE, empty and skippable frames are built by hand below.
+
+//zstdFixtures maps each compressed fixture to the file it decompresses to.
+var zstdFixtures = map[string]string{
+	"reads.fasta.zst":      "reads.fasta",
+	"reads_19.fasta.zst":   "reads.fasta",
+	"reads_fast.fasta.zst": "reads.fasta",
+	"small.fasta.zst":      "small.fasta",
+	"runs.txt.zst":         "runs.txt",
+}
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func decompressZstd(data []byte) ([]byte, error) {
+	return io.ReadAll(newZstdReader(bytes.NewReader(data)))
+}
+
+//zstdFrame builds a frame with no content size or checksum, a 128 KiB window,
+//and the given blocks, each a complete block (header included).
+func zstdFrame(blocks ...[]byte) []byte {
+	frame := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x00, 0x38}
+	for _, block := range blocks {
+		frame = append(frame, block...)
+	}
+	return frame
+}
+
+//zstdBlock builds a block header followed by its content. For an RLE block,
+//size is the number of times the one byte of content is repeated.
+func zstdBlock(last bool, blockType, size int, content []byte) []byte {
+	header := blockType<<1 | size<<3
+	if last {
+		header |= 1
+	}
+	return append([]byte{byte(header), byte(header >> 8), byte(header >> 16)}, content...)
+}
+
+//skippableFrame builds a skippable frame carrying size bytes of junk.
+func skippableFrame(size int) []byte {
+	frame := make([]byte, 8, 8+size)
+	binary.LittleEndian.PutUint32(frame, zstdSkippableMagic+3)
+	binary.LittleEndian.PutUint32(frame[4:], uint32(size))
+	return append(frame, bytes.Repeat([]byte{0xAB}, size)...)
+}
+
+func TestZstdFixtures(t *testing.T) {
+	for name, plain := range zstdFixtures {
+		t.Run(name, func(t *testing.T) {
+			want := readTestdata(t, plain)
+			got, err := decompressZstd(readTestdata(t, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("decompressed %d bytes that differ from the %d bytes of %s", len(got), len(want), plain)
+			}
+		})
+	}
+}
+
+func TestZstdOneByteAtATime(t *testing.T) {
+	// the decoder must not rely on reads filling its buffers
+	want := readTestdata(t, "reads.fasta")
+	in := iotest.OneByteReader(bytes.NewReader(readTestdata(t, "reads.fasta.zst")))
+	got, err := io.ReadAll(iotest.OneByteReader(newZstdReader(in)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("decompressed data differs from reads.fasta")
+	}
+}
+
+func TestZstdConcatenatedAndSkippableFrames(t *testing.T) {
+	plain := readTestdata(t, "reads.fasta")
+	var data []byte
+	data = append(data, skippableFrame(10)...)
+	data = append(data, readTestdata(t, "reads.fasta.zst")...)
+	data = append(data, skippableFrame(0)...)
+	data = append(data, readTestdata(t, "reads_fast.fasta.zst")...)
+	data = append(data, skippableFrame(3)...)
+
+	got, err := decompressZstd(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append(append([]byte{}, plain...), plain...); !bytes.Equal(got, want) {
+		t.Fatalf("decompressed %d bytes, want reads.fasta twice (%d bytes)", len(got), len(want))
+	}
+}
+
+func TestZstdRawAndRLEBlocks(t *testing.T) {
+	data := zstdFrame(
+		zstdBlock(false, 0, 6, []byte(">read\n")),
+		zstdBlock(false, 1, 1000, []byte("A")),
+		zstdBlock(false, 0, 0, nil),
+		zstdBlock(true, 0, 1, []byte("\n")),
+	)
+	want := ">read\n" + strings.Repeat("A", 1000) + "\n"
+
+	got, err := decompressZstd(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestZstdEmpty(t *testing.T) {
+	tests := map[string][]byte{
+		"no input":        nil,
+		"empty frame":     zstdFrame(zstdBlock(true, 0, 0, nil)),
+		"skippable frame": skippableFrame(4),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := decompressZstd(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 0 {
+				t.Fatalf("got %q, want nothing", got)
+			}
+		})
+	}
+}
+
+func TestZstdErrors(t *testing.T) {
+	tests := map[string][]byte{
+		"bad magic":          {0x28, 0xb5, 0x2f, 0xfe, 0x00, 0x38},
+		"reserved bit":       {0x28, 0xb5, 0x2f, 0xfd, 0x08, 0x38},
+		"dictionary":         {0x28, 0xb5, 0x2f, 0xfd, 0x01, 0x38, 0x07},
+		"reserved block":     zstdFrame(zstdBlock(true, 3, 0, nil)),
+		"raw block too big":  zstdFrame(zstdBlock(true, 0, zstdMaxBlockSize+1, nil)),
+		"missing last block": zstdFrame(zstdBlock(false, 0, 1, []byte("A"))),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := decompressZstd(data); err == nil {
+				t.Fatal("no error")
+			}
+		})
+	}
+}
+
+func TestZstdChecksumMismatch(t *testing.T) {
+	data := readTestdata(t, "reads.fasta.zst")
+	// the checksum is the last 4 bytes of the frame
+	data[len(data)-1] ^= 0x01
+	_, err := decompressZstd(data)
+	if err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("got error %v, want a checksum mismatch", err)
+	}
+}
+
+func TestZstdTruncated(t *testing.T) {
+	data := readTestdata(t, "reads_19.fasta.zst")
+	for _, n := range []int{1, 4, 5, 6, 9, 100, len(data) / 2, len(data) - 5, len(data) - 1} {
+		_, err := decompressZstd(data[:n])
+		if err == nil {
+			t.Errorf("no error after %d of %d bytes", n, len(data))
+		}
+	}
+}
+
+func TestZstdCorrupt(t *testing.T) {
+	// damaged input must never make the decoder panic, and with a checksum
+	// it must give an error (or, where the damage doesn't matter, the right
+	// output); reads_fast.fasta.zst has no checksum to catch wrong output
+	for name, plain := range zstdFixtures {
+		want := readTestdata(t, plain)
+		data := readTestdata(t, name)
+		// a couple of hundred places spread over the whole file
+		for i := 4; i < len(data); i += len(data)/200 + 1 {
+			damaged := append([]byte{}, data...)
+			damaged[i] ^= 0x5a
+			got, err := decompressZstd(damaged)
+			if err == nil && !bytes.Equal(got, want) && name != "reads_fast.fasta.zst" {
+				t.Errorf("%s: damaging byte %d gave the wrong output and no error", name, i)
+			}
+		}
+	}
+}
+
+func TestXXHash64(t *testing.T) {
+	// reference values from the xxHash project (seed 0)
+	tests := map[string]uint64{
+		"":    0xef46db3751d8e999,
+		"a":   0xd24ec4f1a98c6e5b,
+		"abc": 0x44bc2cf5ad770999,
+	}
+	for input, want := range tests {
+		h := newXXHash64()
+		h.Write([]byte(input))
+		if got := h.Sum64(); got != want {
+			t.Errorf("xxhash64(%q) = %#x, want %#x", input, got, want)
+		}
+	}
+}
+
+func TestCollectReadsFromCompressedFASTA(t *testing.T) {
+	want, err := CollectReadsFromFASTA(filepath.Join("testdata", "reads.fasta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 200 {
+		t.Fatalf("got %d reads from reads.fasta, want 200", len(want))
+	}
+	for _, name := range []string{"reads.fasta.zst", "reads_19.fasta.zst", "reads_fast.fasta.zst"} {
+		got, err := CollectReadsFromFASTA(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: reads differ from reads.fasta", name)
+		}
+	}
+}
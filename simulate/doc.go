@@ -0,0 +1,4 @@
+//Package simulate generates test data for the assemblers: random genomes,
+//their k-mer compositions, randomly sampled reads (single or paired) and
+//random mutations.
+package simulate
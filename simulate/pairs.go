@@ -0,0 +1,41 @@
+package simulate
+
+import (
+	"math/rand"
+
+	"github.com/kaushikvemparala/Walker/kmer"
+)
+
+// a paired-end library cuts the genome into fragments of roughly the same
+// length (the insert size) and reads a little of each end of every fragment,
+// each end from its own strand: the first mate reads the fragment forwards from
+// its start and the second mate reads it backwards (reverse complemented) from
+// its end, so the two mates face each other.
+
+//SimulatePairedReads samples pairs of reads of length readLength from the ends
+//of fragments of genome, whose lengths are normally distributed around
+//insertSize with standard deviation insertSD (and never shorter than
+//readLength). It samples enough pairs for the reads to cover each position of
+//the genome coverage times on average, and returns the first mates and the
+//second mates, the i-th of one being the mate of the i-th of the other.
+func SimulatePairedReads(genome string, readLength, insertSize, insertSD, coverage int) ([]string, []string) {
+	n := len(genome)
+	numPairs := coverage * n / (2 * readLength)
+	first := make([]string, 0, numPairs)
+	second := make([]string, 0, numPairs)
+
+	for i := 0; i < numPairs; i++ {
+		fragmentLength := insertSize + int(rand.NormFloat64()*float64(insertSD))
+		if fragmentLength < readLength {
+			fragmentLength = readLength
+		}
+		if fragmentLength > n {
+			fragmentLength = n
+		}
+		start := rand.Intn(n - fragmentLength + 1)
+		fragment := genome[start : start+fragmentLength]
+		first = append(first, fragment[:readLength])
+		second = append(second, kmer.ReverseComplement(fragment[fragmentLength-readLength:]))
+	}
+	return first, second
+}
@@ -1,4 +1,4 @@
-package main
+package simulate
 
 import "math/rand"
 
@@ -103,3 +103,34 @@ func Equals(kmers1, kmers2 []string) bool {
 	}
 }
 */
+
+func MutateDNAString(str string, errorRate float64) string {
+	// string concatenation is slow, but generating arrays of bytes is fast
+	symbols := make([]byte, len(str))
+
+	// range over string, flip a coin, and mutate accordingly
+	for i := range str {
+		symbols[i] = MutateDNASymbol(str[i], errorRate)
+	}
+
+	return string(symbols)
+}
+
+//MutateDNASymbol mutates a given DNA symbol with probability equal to error rate given.
+func MutateDNASymbol(symbol byte, errorRate float64) byte {
+	x := rand.Float64()
+
+	if x <= errorRate {
+		//mutate!
+		newSymbol := RandomDNASymbol()
+		// if new == symbol, we don't want to return it
+		for newSymbol == symbol {
+			// generate another one
+			newSymbol = RandomDNASymbol()
+		}
+		// we know we have a different symbol
+		return newSymbol
+	} else {
+		return symbol
+	}
+}
@@ -0,0 +1,3 @@
+//Package stats summarizes the lengths of reads and contigs, either from a
+//slice of strings or in a single pass over a seqio.ReadStream.
+package stats
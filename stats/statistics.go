@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kaushikvemparala/Walker/seqio"
+)
+
+func AverageStringLength(patterns []string) float64 {
+	numStrings := len(patterns)
+	return float64(TotalStringLength(patterns)) / float64(numStrings)
+}
+
+func TotalStringLength(patterns []string) int {
+	s := 0
+	for _, pattern := range patterns {
+		s += len(pattern)
+	}
+	return s
+}
+
+func MinimumStringLength(patterns []string) int {
+	m := len(patterns[0])
+	for i := 1; i < len(patterns); i++ {
+		if len(patterns[i]) < m {
+			m = len(patterns[i])
+		}
+	}
+	return m
+}
+
+func MaximumStringLength(patterns []string) int {
+	m := len(patterns[0])
+	for i := 1; i < len(patterns); i++ {
+		if len(patterns[i]) > m {
+			m = len(patterns[i])
+		}
+	}
+	return m
+}
+
+func PrintStatistics(patterns []string) {
+	fmt.Println("Minimum length:", MinimumStringLength(patterns))
+	fmt.Println("Maximum length:", MaximumStringLength(patterns))
+	fmt.Println("Total length:", TotalStringLength(patterns))
+	fmt.Println("Average length:", AverageStringLength(patterns))
+}
+
+//N50 returns the largest length L such that strings of length at least L
+//make up at least half of the total length. It's the usual way of saying how
+//contiguous an assembly is: bigger is better.
+func N50(patterns []string) int {
+	lengths := make([]int, len(patterns))
+	for i := range patterns {
+		lengths[i] = len(patterns[i])
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+
+	total := TotalStringLength(patterns)
+	sum := 0
+	for _, length := range lengths {
+		sum += length
+		// comparing 2*sum avoids rounding half of an odd total
+		if 2*sum >= total {
+			return length
+		}
+	}
+	return 0
+}
+
+func DiscardShortReads(reads []seqio.Read, minReadLength int) []seqio.Read {
+	//challenge: why do I go from end of reads backward instead of just ranging?
+	for j := len(reads) - 1; j >= 0; j-- {
+		if len(reads[j].Sequence) < minReadLength {
+			reads = append(reads[:j], reads[j+1:]...)
+		}
+	}
+	return reads
+}
+
+//ReadStatistics summarizes the lengths of a collection of reads. Unlike the
+//functions above, it is built up one read at a time, so it can be computed in
+//a single pass over a ReadStream without holding the reads in memory.
+type ReadStatistics struct {
+	Count   int
+	Minimum int
+	Maximum int
+	Total   int
+}
+
+//Add updates the statistics with one more read.
+func (stats *ReadStatistics) Add(read seqio.Read) {
+	n := len(read.Sequence)
+	if stats.Count == 0 || n < stats.Minimum {
+		stats.Minimum = n
+	}
+	if n > stats.Maximum {
+		stats.Maximum = n
+	}
+	stats.Total += n
+	stats.Count++
+}
+
+//Average returns the average read length.
+func (stats ReadStatistics) Average() float64 {
+	if stats.Count == 0 {
+		return 0.0
+	}
+	return float64(stats.Total) / float64(stats.Count)
+}
+
+//Print prints the statistics in the same format as PrintStatistics.
+func (stats ReadStatistics) Print() {
+	fmt.Println("Number of reads:", stats.Count)
+	fmt.Println("Minimum length:", stats.Minimum)
+	fmt.Println("Maximum length:", stats.Maximum)
+	fmt.Println("Total length:", stats.Total)
+	fmt.Println("Average length:", stats.Average())
+}
+
+//StreamStatistics drains a stream and returns statistics of the reads in it.
+func StreamStatistics(stream seqio.ReadStream) (ReadStatistics, error) {
+	var stats ReadStatistics
+	for stream.Next() {
+		stats.Add(stream.Read())
+	}
+	return stats, stream.Err()
+}
+
+//StatisticsStream passes the reads of a stream on unchanged, adding each one to
+//Statistics as it goes by, so statistics can be gathered in the same pass as
+//whatever else reads the stream.
+type StatisticsStream struct {
+	seqio.ReadStream
+	Statistics ReadStatistics
+}
+
+//NewStatisticsStream wraps stream in a StatisticsStream.
+func NewStatisticsStream(stream seqio.ReadStream) *StatisticsStream {
+	return &StatisticsStream{ReadStream: stream}
+}
+
+func (stream *StatisticsStream) Next() bool {
+	if !stream.ReadStream.Next() {
+		return false
+	}
+	stream.Statistics.Add(stream.ReadStream.Read())
+	return true
+}